@@ -0,0 +1,192 @@
+package blobData
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+var ErrBlobNotFound = errors.New("blob not found in archive")
+
+// 本地 blob 存档：blob 按 versioned hash 存放在 blobs/ 目录下（内容为 blob ++ commitment），
+// index.jsonl 记录交易哈希、区块号到 versioned hash 的对应关系
+type Archive struct {
+	dir string
+	mu  sync.Mutex
+}
+
+type ArchiveEntry struct {
+	TxHash          common.Hash   `json:"txHash"`
+	BlockNumber     uint64        `json:"blockNumber"`
+	VersionedHashes []common.Hash `json:"versionedHashes"`
+}
+
+type ArchivedBlob struct {
+	VersionedHash common.Hash
+	Blob          kzg4844.Blob
+	Commitment    kzg4844.Commitment
+}
+
+func OpenArchive(dir string) (*Archive, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "blobs"), 0755); err != nil {
+		return nil, err
+	}
+	return &Archive{dir: dir}, nil
+}
+
+func (a *Archive) blobPath(hash common.Hash) string {
+	hex := hash.Hex()
+	return filepath.Join(a.dir, "blobs", hex[2:4], hex+".blob")
+}
+
+// 保存一笔交易的 blob，已存在的 blob 不会重复写入；写入前校验 commitment 与 versioned hash 一致
+func (a *Archive) Store(txHash common.Hash, blockNumber uint64, blobHashes []common.Hash, blobs []kzg4844.Blob) error {
+	if len(blobHashes) != len(blobs) {
+		return fmt.Errorf("blob count mismatch: %d hashes, %d blobs", len(blobHashes), len(blobs))
+	}
+
+	for i, hash := range blobHashes {
+		path := a.blobPath(hash)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+
+		commitment, err := kzg4844.BlobToCommitment(&blobs[i])
+		if err != nil {
+			return fmt.Errorf("blob %s: %w", hash.Hex(), err)
+		}
+		if KZGToVersionedHash(commitment) != hash {
+			return fmt.Errorf("blob %s does not match its versioned hash", hash.Hex())
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		data := append(append([]byte{}, blobs[i][:]...), commitment[:]...)
+		// 先写临时文件再重命名，避免中断时留下不完整的 blob
+		if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+			return err
+		}
+		if err := os.Rename(path+".tmp", path); err != nil {
+			return err
+		}
+	}
+
+	return a.appendIndex(ArchiveEntry{TxHash: txHash, BlockNumber: blockNumber, VersionedHashes: blobHashes})
+}
+
+func (a *Archive) appendIndex(entry ArchiveEntry) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	f, err := os.OpenFile(filepath.Join(a.dir, "index.jsonl"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// 读取索引，同一交易只保留最后一条记录
+func (a *Archive) Entries() ([]ArchiveEntry, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	f, err := os.Open(filepath.Join(a.dir, "index.jsonl"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		entries []ArchiveEntry
+		seen    = make(map[common.Hash]int)
+		scanner = bufio.NewScanner(f)
+	)
+	for scanner.Scan() {
+		var entry ArchiveEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid index line: %w", err)
+		}
+		if i, ok := seen[entry.TxHash]; ok {
+			entries[i] = entry
+			continue
+		}
+		seen[entry.TxHash] = len(entries)
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+func (a *Archive) LookupTx(txHash common.Hash) (*ArchiveEntry, error) {
+	entries, err := a.Entries()
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].TxHash == txHash {
+			return &entries[i], nil
+		}
+	}
+	return nil, ErrBlobNotFound
+}
+
+func (a *Archive) LookupBlock(blockNumber uint64) ([]ArchiveEntry, error) {
+	entries, err := a.Entries()
+	if err != nil {
+		return nil, err
+	}
+	var found []ArchiveEntry
+	for _, entry := range entries {
+		if entry.BlockNumber == blockNumber {
+			found = append(found, entry)
+		}
+	}
+	return found, nil
+}
+
+// 读取 blob 并重新计算 commitment，校验与存储的 commitment 及 versioned hash 一致
+func (a *Archive) Get(hash common.Hash) (*ArchivedBlob, error) {
+	data, err := os.ReadFile(a.blobPath(hash))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrBlobNotFound, hash.Hex())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stored := &ArchivedBlob{VersionedHash: hash}
+	if len(data) != len(stored.Blob)+len(stored.Commitment) {
+		return nil, fmt.Errorf("archived blob %s has invalid size %d", hash.Hex(), len(data))
+	}
+	copy(stored.Blob[:], data)
+	copy(stored.Commitment[:], data[len(stored.Blob):])
+
+	commitment, err := kzg4844.BlobToCommitment(&stored.Blob)
+	if err != nil {
+		return nil, fmt.Errorf("archived blob %s: %w", hash.Hex(), err)
+	}
+	if commitment != stored.Commitment {
+		return nil, fmt.Errorf("archived blob %s does not match its stored commitment", hash.Hex())
+	}
+	if KZGToVersionedHash(commitment) != hash {
+		return nil, fmt.Errorf("archived blob %s does not match its versioned hash", hash.Hex())
+	}
+	return stored, nil
+}
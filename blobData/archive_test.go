@@ -0,0 +1,71 @@
+package blobData
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+func TestArchive(t *testing.T) {
+	archive, err := OpenArchive(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sidecar, hash := makeTestSidecar(t, "0", []byte("archived"))
+	txHash := common.Hash{0xaa}
+
+	if err := archive.Store(txHash, 42, []common.Hash{hash}, []kzg4844.Blob{sidecar.Blob}); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	// 重复保存不会产生重复的索引记录
+	if err := archive.Store(txHash, 42, []common.Hash{hash}, []kzg4844.Blob{sidecar.Blob}); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+
+	entry, err := archive.LookupTx(txHash)
+	if err != nil {
+		t.Fatalf("LookupTx failed: %v", err)
+	}
+	if entry.BlockNumber != 42 || len(entry.VersionedHashes) != 1 || entry.VersionedHashes[0] != hash {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	entries, err := archive.LookupBlock(42)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("LookupBlock returned %d entries, err %v", len(entries), err)
+	}
+
+	stored, err := archive.Get(hash)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if stored.Blob != sidecar.Blob || stored.Commitment != sidecar.KZGCommitment {
+		t.Error("archived blob does not match")
+	}
+
+	// 篡改后的 blob 无法通过校验
+	path := archive.blobPath(hash)
+	data, _ := os.ReadFile(path)
+	data[1] ^= 0x01
+	os.WriteFile(path, data, 0644)
+	if _, err := archive.Get(hash); err == nil {
+		t.Error("expected verification error for corrupted blob")
+	}
+
+	if _, err := archive.Get(common.Hash{0x01}); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("expected ErrBlobNotFound, got %v", err)
+	}
+}
+
+func TestArchiveRejectsMismatchedHash(t *testing.T) {
+	archive, err := OpenArchive(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sidecar, _ := makeTestSidecar(t, "0", []byte("archived"))
+	if err := archive.Store(common.Hash{0xaa}, 1, []common.Hash{{0x01}}, []kzg4844.Blob{sidecar.Blob}); err == nil {
+		t.Fatal("expected error for mismatched versioned hash")
+	}
+}
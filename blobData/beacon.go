@@ -0,0 +1,166 @@
+package blobData
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// 信标节点返回的 blob sidecar
+type BlobSidecar struct {
+	Index         string             `json:"index"`
+	Blob          kzg4844.Blob       `json:"blob"`
+	KZGCommitment kzg4844.Commitment `json:"kzg_commitment"`
+	KZGProof      kzg4844.Proof      `json:"kzg_proof"`
+}
+
+// 执行层 RPC 在交易打包后不再返回 sidecar，需要通过信标节点的 blob_sidecars 接口获取
+type BeaconClient struct {
+	baseURL string
+	http    *http.Client
+
+	mu             sync.Mutex
+	genesisTime    uint64
+	secondsPerSlot uint64
+}
+
+func NewBeaconClient(baseURL string) *BeaconClient {
+	return &BeaconClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    http.DefaultClient,
+	}
+}
+
+func (c *BeaconClient) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("beacon request %s failed: %s", path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// 获取创世时间和 slot 间隔，结果会被缓存
+func (c *BeaconClient) chainTiming(ctx context.Context) (uint64, uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.secondsPerSlot != 0 {
+		return c.genesisTime, c.secondsPerSlot, nil
+	}
+
+	var genesis struct {
+		Data struct {
+			GenesisTime string `json:"genesis_time"`
+		} `json:"data"`
+	}
+	if err := c.get(ctx, "/eth/v1/beacon/genesis", &genesis); err != nil {
+		return 0, 0, err
+	}
+	genesisTime, err := strconv.ParseUint(genesis.Data.GenesisTime, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid genesis time %q: %w", genesis.Data.GenesisTime, err)
+	}
+
+	var spec struct {
+		Data map[string]string `json:"data"`
+	}
+	if err := c.get(ctx, "/eth/v1/config/spec", &spec); err != nil {
+		return 0, 0, err
+	}
+	secondsPerSlot, err := strconv.ParseUint(spec.Data["SECONDS_PER_SLOT"], 10, 64)
+	if err != nil || secondsPerSlot == 0 {
+		return 0, 0, fmt.Errorf("invalid SECONDS_PER_SLOT %q", spec.Data["SECONDS_PER_SLOT"])
+	}
+
+	c.genesisTime, c.secondsPerSlot = genesisTime, secondsPerSlot
+	return genesisTime, secondsPerSlot, nil
+}
+
+// 根据执行层区块时间戳计算对应的 slot
+func (c *BeaconClient) SlotForTimestamp(ctx context.Context, timestamp uint64) (uint64, error) {
+	genesisTime, secondsPerSlot, err := c.chainTiming(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if timestamp < genesisTime {
+		return 0, fmt.Errorf("timestamp %d is before beacon genesis %d", timestamp, genesisTime)
+	}
+	return (timestamp - genesisTime) / secondsPerSlot, nil
+}
+
+func (c *BeaconClient) BlobSidecars(ctx context.Context, blockID string) ([]BlobSidecar, error) {
+	var resp struct {
+		Data []BlobSidecar `json:"data"`
+	}
+	if err := c.get(ctx, "/eth/v1/beacon/blob_sidecars/"+blockID, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// 获取执行层区块（按时间戳）中与 blobHashes 对应的 blob，顺序与 blobHashes 一致
+func (c *BeaconClient) BlobsForTx(ctx context.Context, blockTime uint64, blobHashes []common.Hash) ([]kzg4844.Blob, error) {
+	slot, err := c.SlotForTimestamp(ctx, blockTime)
+	if err != nil {
+		return nil, err
+	}
+	sidecars, err := c.BlobSidecars(ctx, strconv.FormatUint(slot, 10))
+	if err != nil {
+		return nil, err
+	}
+	return MatchSidecars(sidecars, blobHashes)
+}
+
+// 通过 commitment 的 versioned hash 将 sidecar 与交易的 BlobHashes 对应起来
+func MatchSidecars(sidecars []BlobSidecar, blobHashes []common.Hash) ([]kzg4844.Blob, error) {
+	byHash := make(map[common.Hash]*BlobSidecar, len(sidecars))
+	for i := range sidecars {
+		byHash[KZGToVersionedHash(sidecars[i].KZGCommitment)] = &sidecars[i]
+	}
+
+	blobs := make([]kzg4844.Blob, len(blobHashes))
+	for i, hash := range blobHashes {
+		sidecar, ok := byHash[hash]
+		if !ok {
+			return nil, fmt.Errorf("no sidecar found for versioned hash %s", hash.Hex())
+		}
+		blobs[i] = sidecar.Blob
+	}
+	return blobs, nil
+}
+
+// 将 blob 以 <versionedHash>.blob 的文件名保存到 dir
+func SaveBlobs(dir string, blobHashes []common.Hash, blobs []kzg4844.Blob) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(blobs))
+	for i := range blobs {
+		path := filepath.Join(dir, blobHashes[i].Hex()+".blob")
+		if err := os.WriteFile(path, blobs[i][:], 0644); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
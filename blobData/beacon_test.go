@@ -0,0 +1,86 @@
+package blobData
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+func newBeaconStandIn(t *testing.T, slot string, sidecars []BlobSidecar) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/eth/v1/beacon/genesis", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"genesis_time":"1000"}}`))
+	})
+	mux.HandleFunc("/eth/v1/config/spec", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"SECONDS_PER_SLOT":"12"}}`))
+	})
+	mux.HandleFunc("/eth/v1/beacon/blob_sidecars/"+slot, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": sidecars})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func makeTestSidecar(t *testing.T, index string, payload []byte) (BlobSidecar, common.Hash) {
+	var blob kzg4844.Blob
+	copy(blob[1:32], payload)
+	commitment, err := kzg4844.BlobToCommitment(&blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return BlobSidecar{Index: index, Blob: blob, KZGCommitment: commitment}, KZGToVersionedHash(commitment)
+}
+
+func TestBlobsForTx(t *testing.T) {
+	first, firstHash := makeTestSidecar(t, "0", []byte("first"))
+	second, secondHash := makeTestSidecar(t, "1", []byte("second"))
+
+	// 时间戳 1000 + 12*5 对应 slot 5
+	srv := newBeaconStandIn(t, "5", []BlobSidecar{first, second})
+	client := NewBeaconClient(srv.URL + "/")
+
+	blobs, err := client.BlobsForTx(context.Background(), 1060, []common.Hash{secondHash, firstHash})
+	if err != nil {
+		t.Fatalf("BlobsForTx failed: %v", err)
+	}
+	if len(blobs) != 2 {
+		t.Fatalf("got %d blobs, want 2", len(blobs))
+	}
+	if got := DecodeBlob(blobs[0]); !bytes.Equal(got, []byte("second")) {
+		t.Errorf("blob 0 decoded to %q, want %q", got, "second")
+	}
+	if got := DecodeBlob(blobs[1]); !bytes.Equal(got, []byte("first")) {
+		t.Errorf("blob 1 decoded to %q, want %q", got, "first")
+	}
+
+	dir := t.TempDir()
+	paths, err := SaveBlobs(dir, []common.Hash{secondHash, firstHash}, blobs)
+	if err != nil {
+		t.Fatalf("SaveBlobs failed: %v", err)
+	}
+	saved, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(saved, blobs[0][:]) {
+		t.Error("saved blob does not match")
+	}
+}
+
+func TestBlobsForTxMissingSidecar(t *testing.T) {
+	first, _ := makeTestSidecar(t, "0", []byte("first"))
+	srv := newBeaconStandIn(t, "0", []BlobSidecar{first})
+	client := NewBeaconClient(srv.URL)
+
+	if _, err := client.BlobsForTx(context.Background(), 1000, []common.Hash{{0x01}}); err == nil {
+		t.Fatal("expected error for unmatched versioned hash")
+	}
+}
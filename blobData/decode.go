@@ -0,0 +1,16 @@
+package blobData
+
+import (
+	"bytes"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// 按 blobSender.EncodeBlobs 的格式解码：每个 field element 去掉首字节，末尾补的 0 会被去掉
+func DecodeBlob(blob kzg4844.Blob) []byte {
+	data := make([]byte, 0, len(blob)/32*31)
+	for i := 0; i < len(blob); i += 32 {
+		data = append(data, blob[i+1:i+32]...)
+	}
+	return bytes.TrimRight(data, "\x00")
+}
@@ -0,0 +1,55 @@
+package blobData
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// 将一笔交易携带的 blob 解码为可打印的结果，不同 rollup 的 blob 格式实现各自的解码器
+type BlobDecoder interface {
+	Name() string
+	Decode(blobs []kzg4844.Blob) (interface{}, error)
+}
+
+var decoders = map[string]BlobDecoder{}
+
+func RegisterDecoder(decoder BlobDecoder) {
+	decoders[decoder.Name()] = decoder
+}
+
+func GetDecoder(name string) (BlobDecoder, error) {
+	decoder, ok := decoders[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown blob decoder %q, available: %v", name, DecoderNames())
+	}
+	return decoder, nil
+}
+
+func DecoderNames() []string {
+	names := make([]string, 0, len(decoders))
+	for name := range decoders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	RegisterDecoder(RawDecoder{})
+	RegisterDecoder(OPStackDecoder{})
+}
+
+// blobSender.EncodeBlobs 格式的解码器，每个 blob 输出一个十六进制字符串
+type RawDecoder struct{}
+
+func (RawDecoder) Name() string { return "raw" }
+
+func (RawDecoder) Decode(blobs []kzg4844.Blob) (interface{}, error) {
+	decoded := make([]string, len(blobs))
+	for i, blob := range blobs {
+		decoded[i] = fmt.Sprintf("0x%x", DecodeBlob(blob))
+	}
+	return decoded, nil
+}
@@ -0,0 +1,170 @@
+package blobData
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// OP Stack blob 编码：每 4 个 field element 为一轮，存放 127 字节数据，
+// 第一个 field element 的 [1] 为编码版本，[2:5] 为大端 3 字节的数据长度
+const (
+	opEncodingVersion  = 0
+	opRounds           = 1024
+	opMaxBlobDataSize  = (4*31+3)*opRounds - 4
+	opDerivationV0     = 0
+	opFrameOverhead    = 16 + 2 + 4 + 1
+	opChannelIDLength  = 16
+	opBlobVersionIndex = 1
+)
+
+var ErrInvalidFieldElement = errors.New("invalid field element")
+
+// batcher 交易中的一个 frame
+type OPFrame struct {
+	BlobIndex   int
+	ChannelID   [opChannelIDLength]byte
+	FrameNumber uint16
+	DataLength  uint32
+	IsLast      bool
+}
+
+func (f OPFrame) String() string {
+	return fmt.Sprintf("blob=%d channel=0x%x frame=%d size=%d last=%t", f.BlobIndex, f.ChannelID, f.FrameNumber, f.DataLength, f.IsLast)
+}
+
+// 解码 OP Stack batcher 发布的 blob，输出其中每个 frame 的 channel、编号和大小
+type OPStackDecoder struct{}
+
+func (OPStackDecoder) Name() string { return "opstack" }
+
+func (OPStackDecoder) Decode(blobs []kzg4844.Blob) (interface{}, error) {
+	var frames []OPFrame
+	for i, blob := range blobs {
+		data, err := DecodeOPBlob(blob)
+		if err != nil {
+			return frames, fmt.Errorf("blob %d: %w", i, err)
+		}
+		blobFrames, err := ParseOPFrames(data)
+		if err != nil {
+			return frames, fmt.Errorf("blob %d: %w", i, err)
+		}
+		for j := range blobFrames {
+			blobFrames[j].BlobIndex = i
+		}
+		frames = append(frames, blobFrames...)
+	}
+	return frames, nil
+}
+
+// 还原 OP Stack 编码的 blob 数据
+func DecodeOPBlob(blob kzg4844.Blob) ([]byte, error) {
+	if blob[opBlobVersionIndex] != opEncodingVersion {
+		return nil, fmt.Errorf("unsupported blob encoding version %d", blob[opBlobVersionIndex])
+	}
+	outputLen := uint32(blob[2])<<16 | uint32(blob[3])<<8 | uint32(blob[4])
+	if outputLen > opMaxBlobDataSize {
+		return nil, fmt.Errorf("blob data length %d exceeds maximum %d", outputLen, opMaxBlobDataSize)
+	}
+
+	// 第 0 轮的第一个 field element 只有 27 字节数据
+	output := make([]byte, opMaxBlobDataSize)
+	copy(output[0:27], blob[5:32])
+
+	var (
+		opos    = 28
+		ipos    = 32
+		err     error
+		encoded [4]byte
+	)
+	encoded[0] = blob[0]
+	for i := 1; i < 4; i++ {
+		if encoded[i], opos, ipos, err = decodeOPFieldElement(blob, opos, ipos, output); err != nil {
+			return nil, err
+		}
+	}
+	opos = reassembleOPBytes(opos, encoded, output)
+
+	for round := 1; round < opRounds && opos < int(outputLen); round++ {
+		for j := 0; j < 4; j++ {
+			if encoded[j], opos, ipos, err = decodeOPFieldElement(blob, opos, ipos, output); err != nil {
+				return nil, err
+			}
+		}
+		opos = reassembleOPBytes(opos, encoded, output)
+	}
+
+	for i := int(outputLen); i < len(output); i++ {
+		if output[i] != 0 {
+			return nil, fmt.Errorf("non-zero data in field element %d beyond data length", i/32)
+		}
+	}
+	for ; ipos < len(blob); ipos++ {
+		if blob[ipos] != 0 {
+			return nil, fmt.Errorf("non-zero data at blob position %d beyond data length", ipos)
+		}
+	}
+	return output[:outputLen], nil
+}
+
+// 将 field element 的低 31 字节写入输出，返回首字节中的 6 位数据
+func decodeOPFieldElement(blob kzg4844.Blob, opos, ipos int, output []byte) (byte, int, int, error) {
+	if blob[ipos]&0b1100_0000 != 0 {
+		return 0, 0, 0, fmt.Errorf("%w at position %d", ErrInvalidFieldElement, ipos)
+	}
+	copy(output[opos:], blob[ipos+1:ipos+32])
+	return blob[ipos], opos + 32, ipos + 32, nil
+}
+
+// 将 4 个 6 位数据重新组合成 3 个字节，放回本轮对应的位置
+func reassembleOPBytes(opos int, encoded [4]byte, output []byte) int {
+	opos--
+	x := (encoded[0] & 0b0011_1111) | ((encoded[1] & 0b0011_0000) << 2)
+	y := (encoded[1] & 0b0000_1111) | ((encoded[3] & 0b0000_1111) << 4)
+	z := (encoded[2] & 0b0011_1111) | ((encoded[3] & 0b0011_0000) << 2)
+	output[opos-32] = z
+	output[opos-32*2] = y
+	output[opos-32*3] = x
+	return opos
+}
+
+// 解析 batcher 数据：1 字节 derivation 版本，之后为连续的 frame
+// frame = channel_id(16) ++ frame_number(uint16) ++ frame_data_length(uint32) ++ frame_data ++ is_last(1)
+func ParseOPFrames(data []byte) ([]OPFrame, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty batcher data")
+	}
+	if data[0] != opDerivationV0 {
+		return nil, fmt.Errorf("unsupported derivation version %d", data[0])
+	}
+
+	var frames []OPFrame
+	rest := data[1:]
+	for len(rest) > 0 {
+		if len(rest) < opFrameOverhead {
+			return frames, fmt.Errorf("truncated frame header: %d bytes left", len(rest))
+		}
+		var frame OPFrame
+		copy(frame.ChannelID[:], rest[:opChannelIDLength])
+		frame.FrameNumber = binary.BigEndian.Uint16(rest[16:18])
+		frame.DataLength = binary.BigEndian.Uint32(rest[18:22])
+
+		end := 22 + int(frame.DataLength)
+		if frame.DataLength > opMaxBlobDataSize || end >= len(rest) {
+			return frames, fmt.Errorf("truncated frame data: length %d, %d bytes left", frame.DataLength, len(rest)-22)
+		}
+		switch rest[end] {
+		case 0:
+		case 1:
+			frame.IsLast = true
+		default:
+			return frames, fmt.Errorf("invalid is_last byte %d", rest[end])
+		}
+
+		frames = append(frames, frame)
+		rest = rest[end+1:]
+	}
+	return frames, nil
+}
@@ -0,0 +1,119 @@
+package blobData
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// OP Stack 的 blob 编码，用于构造测试数据
+func encodeOPBlob(t *testing.T, data []byte) kzg4844.Blob {
+	var (
+		blob        kzg4844.Blob
+		readOffset  int
+		writeOffset int
+		buf31       [31]byte
+	)
+	read1 := func() byte {
+		if readOffset >= len(data) {
+			return 0
+		}
+		readOffset++
+		return data[readOffset-1]
+	}
+	read31 := func() {
+		buf31 = [31]byte{}
+		if readOffset < len(data) {
+			readOffset += copy(buf31[:], data[readOffset:])
+		}
+	}
+	write := func(v byte) {
+		blob[writeOffset] = v
+		copy(blob[writeOffset+1:], buf31[:])
+		writeOffset += 32
+	}
+
+	for round := 0; round < opRounds && readOffset < len(data); round++ {
+		if round == 0 {
+			buf31 = [31]byte{opEncodingVersion, byte(len(data) >> 16), byte(len(data) >> 8), byte(len(data))}
+			readOffset += copy(buf31[4:], data)
+		} else {
+			read31()
+		}
+		x := read1()
+		write(x & 0b0011_1111)
+
+		read31()
+		y := read1()
+		write((y & 0b0000_1111) | ((x & 0b1100_0000) >> 2))
+
+		read31()
+		z := read1()
+		write(z & 0b0011_1111)
+
+		read31()
+		write(((z & 0b1100_0000) >> 2) | ((y & 0b1111_0000) >> 4))
+	}
+	if readOffset < len(data) {
+		t.Fatalf("data too large for one blob: %d", len(data))
+	}
+	return blob
+}
+
+func appendFrame(data []byte, channel byte, number uint16, payload []byte, last bool) []byte {
+	data = append(data, bytes.Repeat([]byte{channel}, 16)...)
+	data = binary.BigEndian.AppendUint16(data, number)
+	data = binary.BigEndian.AppendUint32(data, uint32(len(payload)))
+	data = append(data, payload...)
+	if last {
+		return append(data, 1)
+	}
+	return append(data, 0)
+}
+
+func TestOPStackDecoder(t *testing.T) {
+	payload := make([]byte, 50000)
+	for i := range payload {
+		payload[i] = byte(i * 7)
+	}
+	data := []byte{opDerivationV0}
+	data = appendFrame(data, 0xaa, 0, payload, false)
+	data = appendFrame(data, 0xaa, 1, []byte{1, 2, 3}, true)
+
+	blob := encodeOPBlob(t, data)
+	decoded, err := DecodeOPBlob(blob)
+	if err != nil {
+		t.Fatalf("DecodeOPBlob failed: %v", err)
+	}
+	if !bytes.Equal(decoded, data) {
+		t.Fatal("decoded data does not match encoded data")
+	}
+
+	out, err := OPStackDecoder{}.Decode([]kzg4844.Blob{blob})
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	frames := out.([]OPFrame)
+	if len(frames) != 2 {
+		t.Fatalf("got %d frames, want 2", len(frames))
+	}
+	if frames[0].FrameNumber != 0 || frames[0].DataLength != uint32(len(payload)) || frames[0].IsLast {
+		t.Errorf("unexpected first frame: %v", frames[0])
+	}
+	if frames[1].FrameNumber != 1 || frames[1].DataLength != 3 || !frames[1].IsLast {
+		t.Errorf("unexpected second frame: %v", frames[1])
+	}
+	if frames[1].ChannelID[0] != 0xaa {
+		t.Errorf("unexpected channel id: %x", frames[1].ChannelID)
+	}
+}
+
+func TestOPStackDecoderRejectsInvalidFieldElement(t *testing.T) {
+	blob := encodeOPBlob(t, []byte{opDerivationV0})
+	blob[32] = 0b1000_0000
+	if _, err := DecodeOPBlob(blob); err == nil {
+		t.Fatal("expected error for invalid field element")
+	}
+}
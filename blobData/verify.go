@@ -0,0 +1,96 @@
+package blobData
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sync"
+
+	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// 单个 blob 的校验结果
+type BlobCheck struct {
+	Index         int
+	VersionedHash common.Hash // 由 commitment 计算的 versioned hash
+	ExpectedHash  common.Hash // 交易中 BlobHashes 对应的值
+	CommitmentOK  bool        // blob 重新计算的 commitment 与 sidecar 一致
+	ProofOK       bool        // KZG proof 校验通过
+	HashOK        bool        // versioned hash 与 BlobHashes 一致
+	Err           error
+}
+
+func (c BlobCheck) OK() bool {
+	return c.CommitmentOK && c.ProofOK && c.HashOK
+}
+
+func KZGToVersionedHash(commitment kzg4844.Commitment) common.Hash {
+	return kzg4844.CalcBlobHashV1(sha256.New(), &commitment)
+}
+
+// 批量校验使用的 KZG 上下文，初始化需要加载 trusted setup，只在第一次校验时创建
+var (
+	kzgOnce    sync.Once
+	kzgContext *gokzg4844.Context
+	kzgErr     error
+)
+
+// 批量校验所有 blob 的 KZG 证明，只有批量校验失败时才逐个校验，以便报告具体是哪个 blob 的证明无效
+func verifyProofs(sidecar *types.BlobTxSidecar) []error {
+	errs := make([]error, len(sidecar.Blobs))
+	kzgOnce.Do(func() { kzgContext, kzgErr = gokzg4844.NewContext4096Secure() })
+	if kzgErr == nil {
+		blobs := make([]gokzg4844.Blob, len(sidecar.Blobs))
+		commitments := make([]gokzg4844.KZGCommitment, len(sidecar.Commitments))
+		proofs := make([]gokzg4844.KZGProof, len(sidecar.Proofs))
+		for i := range sidecar.Blobs {
+			blobs[i] = gokzg4844.Blob(sidecar.Blobs[i])
+			commitments[i] = gokzg4844.KZGCommitment(sidecar.Commitments[i])
+			proofs[i] = gokzg4844.KZGProof(sidecar.Proofs[i])
+		}
+		if kzgContext.VerifyBlobKZGProofBatch(blobs, commitments, proofs) == nil {
+			return errs
+		}
+	}
+	for i := range sidecar.Blobs {
+		errs[i] = kzg4844.VerifyBlobProof(&sidecar.Blobs[i], sidecar.Commitments[i], sidecar.Proofs[i])
+	}
+	return errs
+}
+
+// 校验 sidecar 与交易 BlobHashes 是否一致，逐个 blob 返回结果
+func VerifySidecar(blobHashes []common.Hash, sidecar *types.BlobTxSidecar) ([]BlobCheck, error) {
+	if sidecar == nil {
+		return nil, fmt.Errorf("missing sidecar")
+	}
+	if len(sidecar.Blobs) != len(blobHashes) || len(sidecar.Commitments) != len(blobHashes) || len(sidecar.Proofs) != len(blobHashes) {
+		return nil, fmt.Errorf("sidecar size mismatch: %d hashes, %d blobs, %d commitments, %d proofs",
+			len(blobHashes), len(sidecar.Blobs), len(sidecar.Commitments), len(sidecar.Proofs))
+	}
+
+	proofErrs := verifyProofs(sidecar)
+	checks := make([]BlobCheck, len(blobHashes))
+	for i := range blobHashes {
+		check := BlobCheck{
+			Index:         i,
+			VersionedHash: KZGToVersionedHash(sidecar.Commitments[i]),
+			ExpectedHash:  blobHashes[i],
+			ProofOK:       proofErrs[i] == nil,
+		}
+		check.HashOK = check.VersionedHash == check.ExpectedHash
+
+		commitment, err := kzg4844.BlobToCommitment(&sidecar.Blobs[i])
+		if err != nil {
+			check.Err = err
+		} else {
+			check.CommitmentOK = commitment == sidecar.Commitments[i]
+		}
+		if check.Err == nil {
+			check.Err = proofErrs[i]
+		}
+		checks[i] = check
+	}
+	return checks, nil
+}
@@ -0,0 +1,82 @@
+package blobData
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// 生成两个 blob 的合法 sidecar 及其 versioned hash
+func makeVerifySidecar(t *testing.T) (*types.BlobTxSidecar, []common.Hash) {
+	sidecar := new(types.BlobTxSidecar)
+	var hashes []common.Hash
+	for _, payload := range []string{"first", "second"} {
+		var blob kzg4844.Blob
+		copy(blob[1:32], payload)
+		commitment, err := kzg4844.BlobToCommitment(&blob)
+		if err != nil {
+			t.Fatal(err)
+		}
+		proof, err := kzg4844.ComputeBlobProof(&blob, commitment)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sidecar.Blobs = append(sidecar.Blobs, blob)
+		sidecar.Commitments = append(sidecar.Commitments, commitment)
+		sidecar.Proofs = append(sidecar.Proofs, proof)
+		hashes = append(hashes, KZGToVersionedHash(commitment))
+	}
+	return sidecar, hashes
+}
+
+func TestVerifySidecar(t *testing.T) {
+	type result struct{ commitment, proof, hash bool }
+	cases := []struct {
+		name   string
+		modify func(sidecar *types.BlobTxSidecar, hashes []common.Hash)
+		want   result // 第一个 blob 的校验结果，第二个 blob 始终全部通过
+	}{
+		{"valid", func(*types.BlobTxSidecar, []common.Hash) {}, result{true, true, true}},
+		{"commitment mismatch", func(sidecar *types.BlobTxSidecar, hashes []common.Hash) {
+			sidecar.Commitments[0] = sidecar.Commitments[1]
+			hashes[0] = KZGToVersionedHash(sidecar.Commitments[0])
+		}, result{false, false, true}},
+		{"proof mismatch", func(sidecar *types.BlobTxSidecar, hashes []common.Hash) {
+			sidecar.Proofs[0] = sidecar.Proofs[1]
+		}, result{true, false, true}},
+		{"versioned hash mismatch", func(sidecar *types.BlobTxSidecar, hashes []common.Hash) {
+			hashes[0] = hashes[1]
+		}, result{true, true, false}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sidecar, hashes := makeVerifySidecar(t)
+			c.modify(sidecar, hashes)
+			checks, err := VerifySidecar(hashes, sidecar)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := (result{checks[0].CommitmentOK, checks[0].ProofOK, checks[0].HashOK}); got != c.want {
+				t.Errorf("blob 0 = %+v, want %+v", got, c.want)
+			}
+			if !checks[1].OK() || checks[1].Err != nil {
+				t.Errorf("blob 1 = %+v, want all checks to pass", checks[1])
+			}
+			if checks[0].OK() != (c.want == result{true, true, true}) {
+				t.Errorf("blob 0 OK = %v", checks[0].OK())
+			}
+		})
+	}
+}
+
+func TestVerifySidecarSizeMismatch(t *testing.T) {
+	sidecar, hashes := makeVerifySidecar(t)
+	if _, err := VerifySidecar(hashes[:1], sidecar); err == nil {
+		t.Error("expected error for mismatched sizes")
+	}
+	if _, err := VerifySidecar(hashes, nil); err == nil {
+		t.Error("expected error for missing sidecar")
+	}
+}
@@ -0,0 +1,241 @@
+package blobFee
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// 单个区块的 blob 费用数据
+type BlockBlobFee struct {
+	Number        uint64   `json:"number"`
+	Time          uint64   `json:"time"`
+	ExcessBlobGas uint64   `json:"excessBlobGas"`
+	BlobGasUsed   uint64   `json:"blobGasUsed"`
+	BlobBaseFee   *big.Int `json:"blobBaseFee"`
+	BlobCount     uint64   `json:"blobCount"`
+}
+
+type FeeSpike struct {
+	Number      uint64   `json:"number"`
+	BlobBaseFee *big.Int `json:"blobBaseFee"`
+	Ratio       float64  `json:"ratio"` // 相对区间中位数的倍数
+}
+
+type FeeStats struct {
+	Blocks           int        `json:"blocks"`
+	TotalBlobs       uint64     `json:"totalBlobs"`
+	AvgBlobsPerBlock float64    `json:"avgBlobsPerBlock"`
+	MinFee           *big.Int   `json:"minFee"`
+	P50Fee           *big.Int   `json:"p50Fee"`
+	P90Fee           *big.Int   `json:"p90Fee"`
+	P99Fee           *big.Int   `json:"p99Fee"`
+	MaxFee           *big.Int   `json:"maxFee"`
+	BelowTargetPct   float64    `json:"belowTargetPct"`
+	AtTargetPct      float64    `json:"atTargetPct"`
+	AboveTargetPct   float64    `json:"aboveTargetPct"`
+	Spikes           []FeeSpike `json:"spikes"`
+}
+
+// 获取区块头的节点接口，*ethclient.Client 满足该接口
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+func FetchBlockBlobFee(ctx context.Context, client HeaderReader, number uint64) (*BlockBlobFee, error) {
+	header, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get header %d: %w", number, err)
+	}
+	if header.ExcessBlobGas == nil || header.BlobGasUsed == nil {
+		return nil, fmt.Errorf("block %d: %w", number, ErrNoBlobFields)
+	}
+	return &BlockBlobFee{
+		Number:        number,
+		Time:          header.Time,
+		ExcessBlobGas: *header.ExcessBlobGas,
+		BlobGasUsed:   *header.BlobGasUsed,
+		BlobBaseFee:   eip4844.CalcBlobFee(*header.ExcessBlobGas),
+		BlobCount:     *header.BlobGasUsed / params.BlobTxBlobGasPerBlob,
+	}, nil
+}
+
+// 并行获取 [start, end] 区间内每个区块的 blob 费用数据，结果按区块号排序。
+// Cancun 之前的区块没有 blob 费用字段，直接跳过；区间内没有任何 Cancun 区块时返回 ErrNoBlobFields。
+// 任意区块获取失败后不再发起新的请求，并取消进行中的请求
+func CollectRange(ctx context.Context, client HeaderReader, start, end uint64, concurrency int) ([]BlockBlobFee, error) {
+	if end < start {
+		return nil, fmt.Errorf("invalid range %d-%d", start, end)
+	}
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		fetched  = make([]*BlockBlobFee, end-start+1)
+		sem      = make(chan struct{}, concurrency)
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for number := start; number <= end; number++ {
+		sem <- struct{}{}
+		if ctx.Err() != nil {
+			<-sem
+			break
+		}
+		wg.Add(1)
+		go func(number uint64) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			block, err := FetchBlockBlobFee(ctx, client, number)
+			if errors.Is(err, ErrNoBlobFields) {
+				return
+			}
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+				return
+			}
+			fetched[number-start] = block
+		}(number)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	blocks := make([]BlockBlobFee, 0, len(fetched))
+	for _, block := range fetched {
+		if block != nil {
+			blocks = append(blocks, *block)
+		}
+	}
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("blocks %d-%d: %w", start, end, ErrNoBlobFields)
+	}
+	return blocks, nil
+}
+
+// 统计区间内的 blob 费用分布、目标用量占比以及费用尖峰（超过中位数 spikeFactor 倍的区块）
+func ComputeStats(blocks []BlockBlobFee, spikeFactor float64) FeeStats {
+	stats := FeeStats{Blocks: len(blocks)}
+	if len(blocks) == 0 {
+		return stats
+	}
+
+	fees := make([]*big.Int, len(blocks))
+	var below, at, above int
+	for i, block := range blocks {
+		fees[i] = block.BlobBaseFee
+		stats.TotalBlobs += block.BlobCount
+		switch {
+		case block.BlobGasUsed < params.BlobTxTargetBlobGasPerBlock:
+			below++
+		case block.BlobGasUsed == params.BlobTxTargetBlobGasPerBlock:
+			at++
+		default:
+			above++
+		}
+	}
+
+	n := float64(len(blocks))
+	stats.AvgBlobsPerBlock = float64(stats.TotalBlobs) / n
+	stats.MinFee = Percentile(fees, 0)
+	stats.P50Fee = Percentile(fees, 50)
+	stats.P90Fee = Percentile(fees, 90)
+	stats.P99Fee = Percentile(fees, 99)
+	stats.MaxFee = Percentile(fees, 100)
+	stats.BelowTargetPct = float64(below) / n * 100
+	stats.AtTargetPct = float64(at) / n * 100
+	stats.AboveTargetPct = float64(above) / n * 100
+
+	median := new(big.Float).SetInt(stats.P50Fee)
+	if stats.P50Fee.Sign() > 0 {
+		for _, block := range blocks {
+			ratio, _ := new(big.Float).Quo(new(big.Float).SetInt(block.BlobBaseFee), median).Float64()
+			if ratio >= spikeFactor {
+				stats.Spikes = append(stats.Spikes, FeeSpike{Number: block.Number, BlobBaseFee: block.BlobBaseFee, Ratio: ratio})
+			}
+		}
+	}
+	return stats
+}
+
+func WriteJSON(w io.Writer, blocks []BlockBlobFee, stats FeeStats) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Blocks []BlockBlobFee `json:"blocks"`
+		Stats  FeeStats       `json:"stats"`
+	}{blocks, stats})
+}
+
+// 先输出每个区块一行，空行后输出 stat,value 形式的统计结果
+func WriteCSV(w io.Writer, blocks []BlockBlobFee, stats FeeStats) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"number", "time", "excessBlobGas", "blobGasUsed", "blobBaseFee", "blobCount"})
+	for _, block := range blocks {
+		cw.Write([]string{
+			strconv.FormatUint(block.Number, 10),
+			strconv.FormatUint(block.Time, 10),
+			strconv.FormatUint(block.ExcessBlobGas, 10),
+			strconv.FormatUint(block.BlobGasUsed, 10),
+			block.BlobBaseFee.String(),
+			strconv.FormatUint(block.BlobCount, 10),
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	cw.Write([]string{"stat", "value"})
+	rows := [][]string{
+		{"blocks", strconv.Itoa(stats.Blocks)},
+		{"totalBlobs", strconv.FormatUint(stats.TotalBlobs, 10)},
+		{"avgBlobsPerBlock", strconv.FormatFloat(stats.AvgBlobsPerBlock, 'f', 3, 64)},
+		{"minFee", bigString(stats.MinFee)},
+		{"p50Fee", bigString(stats.P50Fee)},
+		{"p90Fee", bigString(stats.P90Fee)},
+		{"p99Fee", bigString(stats.P99Fee)},
+		{"maxFee", bigString(stats.MaxFee)},
+		{"belowTargetPct", strconv.FormatFloat(stats.BelowTargetPct, 'f', 2, 64)},
+		{"atTargetPct", strconv.FormatFloat(stats.AtTargetPct, 'f', 2, 64)},
+		{"aboveTargetPct", strconv.FormatFloat(stats.AboveTargetPct, 'f', 2, 64)},
+		{"spikes", strconv.Itoa(len(stats.Spikes))},
+	}
+	for _, spike := range stats.Spikes {
+		rows = append(rows, []string{"spike", fmt.Sprintf("%d:%s:%.2fx", spike.Number, spike.BlobBaseFee, spike.Ratio)})
+	}
+	cw.WriteAll(rows)
+	return cw.Error()
+}
+
+func bigString(v *big.Int) string {
+	if v == nil {
+		return ""
+	}
+	return v.String()
+}
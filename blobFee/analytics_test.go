@@ -0,0 +1,181 @@
+package blobFee
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var errHeader = errors.New("header not available")
+
+// 从 cancun 开始的区块带有 blob 费用字段，区块号为 failAt 时返回 errHeader；number 为 nil 时返回 head
+type fakeChain struct {
+	cancun uint64
+	head   uint64
+	failAt int64
+	calls  atomic.Int32
+}
+
+func (c *fakeChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	c.calls.Add(1)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	n := c.head
+	if number != nil {
+		n = number.Uint64()
+	}
+	if int64(n) == c.failAt {
+		return nil, errHeader
+	}
+	if n < c.cancun {
+		return &types.Header{Number: new(big.Int).SetUint64(n)}, nil
+	}
+	header := blobHeader(n*1000, params.BlobTxBlobGasPerBlob)
+	header.Number = new(big.Int).SetUint64(n)
+	return header, nil
+}
+
+func TestCollectRange(t *testing.T) {
+	chain := &fakeChain{cancun: 5, failAt: -1}
+	blocks, err := CollectRange(context.Background(), chain, 2, 8, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Cancun 之前的区块 2-4 被跳过
+	if len(blocks) != 4 {
+		t.Fatalf("got %d blocks, want 4", len(blocks))
+	}
+	for i, block := range blocks {
+		if block.Number != uint64(5+i) || block.ExcessBlobGas != block.Number*1000 || block.BlobCount != 1 {
+			t.Errorf("block %d = %+v", i, block)
+		}
+	}
+
+	if _, err := CollectRange(context.Background(), chain, 0, 4, 3); !errors.Is(err, ErrNoBlobFields) {
+		t.Errorf("pre-Cancun range: err = %v, want ErrNoBlobFields", err)
+	}
+}
+
+func TestCollectRangeStopsAfterError(t *testing.T) {
+	chain := &fakeChain{failAt: 2}
+	if _, err := CollectRange(context.Background(), chain, 0, 100, 1); !errors.Is(err, errHeader) {
+		t.Fatalf("err = %v, want errHeader", err)
+	}
+	if calls := chain.calls.Load(); calls != 3 {
+		t.Errorf("fetched %d headers, want 3 (no new requests after the failure at block 2)", calls)
+	}
+}
+
+func TestComputeStatsEmpty(t *testing.T) {
+	stats := ComputeStats(nil, 3)
+	if stats.Blocks != 0 || stats.TotalBlobs != 0 || stats.MinFee != nil || stats.Spikes != nil {
+		t.Errorf("stats = %+v, want zero value", stats)
+	}
+}
+
+func TestComputeStatsNoBlobs(t *testing.T) {
+	blocks := []BlockBlobFee{
+		{Number: 1, BlobBaseFee: big.NewInt(1)},
+		{Number: 2, BlobBaseFee: big.NewInt(1)},
+	}
+	stats := ComputeStats(blocks, 3)
+	if stats.Blocks != 2 || stats.TotalBlobs != 0 || stats.AvgBlobsPerBlock != 0 {
+		t.Errorf("blob counts = %+v", stats)
+	}
+	if stats.BelowTargetPct != 100 || stats.AtTargetPct != 0 || stats.AboveTargetPct != 0 {
+		t.Errorf("target pcts = %v/%v/%v, want 100/0/0", stats.BelowTargetPct, stats.AtTargetPct, stats.AboveTargetPct)
+	}
+	if stats.MinFee.Int64() != 1 || stats.MaxFee.Int64() != 1 || len(stats.Spikes) != 0 {
+		t.Errorf("fees = %+v", stats)
+	}
+}
+
+func TestComputeStats(t *testing.T) {
+	target := uint64(params.BlobTxTargetBlobGasPerBlock)
+	blocks := []BlockBlobFee{
+		{Number: 10, BlobGasUsed: 0, BlobBaseFee: big.NewInt(10), BlobCount: 0},
+		{Number: 11, BlobGasUsed: target, BlobBaseFee: big.NewInt(10), BlobCount: 3},
+		{Number: 12, BlobGasUsed: target, BlobBaseFee: big.NewInt(12), BlobCount: 3},
+		{Number: 13, BlobGasUsed: params.MaxBlobGasPerBlock, BlobBaseFee: big.NewInt(40), BlobCount: 6},
+	}
+	stats := ComputeStats(blocks, 3)
+
+	if stats.Blocks != 4 || stats.TotalBlobs != 12 || stats.AvgBlobsPerBlock != 3 {
+		t.Errorf("blob counts = %d/%d/%v", stats.Blocks, stats.TotalBlobs, stats.AvgBlobsPerBlock)
+	}
+	if stats.BelowTargetPct != 25 || stats.AtTargetPct != 50 || stats.AboveTargetPct != 25 {
+		t.Errorf("target pcts = %v/%v/%v, want 25/50/25", stats.BelowTargetPct, stats.AtTargetPct, stats.AboveTargetPct)
+	}
+	if stats.MinFee.Int64() != 10 || stats.P50Fee.Int64() != 10 || stats.P90Fee.Int64() != 40 || stats.MaxFee.Int64() != 40 {
+		t.Errorf("fees = %v/%v/%v/%v", stats.MinFee, stats.P50Fee, stats.P90Fee, stats.MaxFee)
+	}
+	if len(stats.Spikes) != 1 || stats.Spikes[0].Number != 13 || stats.Spikes[0].Ratio != 4 {
+		t.Errorf("spikes = %+v, want block 13 at 4x", stats.Spikes)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	blocks := []BlockBlobFee{
+		{Number: 10, Time: 1000, ExcessBlobGas: 0, BlobGasUsed: 0, BlobBaseFee: big.NewInt(10), BlobCount: 0},
+		{Number: 11, Time: 1012, ExcessBlobGas: 393216, BlobGasUsed: 786432, BlobBaseFee: big.NewInt(40), BlobCount: 6},
+	}
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, blocks, ComputeStats(blocks, 3)); err != nil {
+		t.Fatal(err)
+	}
+	want := `number,time,excessBlobGas,blobGasUsed,blobBaseFee,blobCount
+10,1000,0,0,10,0
+11,1012,393216,786432,40,6
+
+stat,value
+blocks,2
+totalBlobs,6
+avgBlobsPerBlock,3.000
+minFee,10
+p50Fee,10
+p90Fee,40
+p99Fee,40
+maxFee,40
+belowTargetPct,50.00
+atTargetPct,0.00
+aboveTargetPct,50.00
+spikes,1
+spike,11:40:4.00x
+`
+	if buf.String() != want {
+		t.Errorf("csv:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteCSVEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, nil, ComputeStats(nil, 3)); err != nil {
+		t.Fatal(err)
+	}
+	want := `number,time,excessBlobGas,blobGasUsed,blobBaseFee,blobCount
+
+stat,value
+blocks,0
+totalBlobs,0
+avgBlobsPerBlock,0.000
+minFee,
+p50Fee,
+p90Fee,
+p99Fee,
+maxFee,
+belowTargetPct,0.00
+atTargetPct,0.00
+aboveTargetPct,0.00
+spikes,0
+`
+	if buf.String() != want {
+		t.Errorf("csv:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
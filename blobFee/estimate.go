@@ -0,0 +1,152 @@
+package blobFee
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var ErrNoBlobFields = errors.New("blob fee fields not available, chain is pre-Cancun or node is too old")
+
+type EstimateConfig struct {
+	LookAhead     uint64  // 向后预估的区块数，按每个区块 blob 满载的最坏情况计算
+	MarginPct     uint64  // 在预估结果上增加的安全余量百分比
+	HistoryBlocks uint64  // 大于 0 时使用 eth_feeHistory 的历史 blob 费用
+	Percentile    float64 // 历史模式下选取的百分位
+}
+
+// 检查配置是否有效，EstimateBlobFeeCap 会先调用该方法，命令行可在连接节点前调用以尽早报错
+func (cfg EstimateConfig) Validate() error {
+	if cfg.Percentile < 0 || cfg.Percentile > 100 {
+		return fmt.Errorf("invalid percentile %v, must be between 0 and 100", cfg.Percentile)
+	}
+	return nil
+}
+
+// 估算 blob 费用需要的节点接口，RPCClient 包装的 *ethclient.Client 满足该接口
+type FeeClient interface {
+	HeaderReader
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// 为 *ethclient.Client 补充 CallContext，用于 ethclient 未封装 blob 字段的 eth_feeHistory
+type RPCClient struct {
+	*ethclient.Client
+}
+
+func (c RPCClient) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	return c.Client.Client().CallContext(ctx, result, method, args...)
+}
+
+// 从父区块开始，假设之后 lookAhead 个区块 blob 全部用满，计算届时的 blob 基础费用
+func ProjectBlobFee(parent *types.Header, lookAhead uint64) (*big.Int, error) {
+	if parent.ExcessBlobGas == nil || parent.BlobGasUsed == nil {
+		return nil, ErrNoBlobFields
+	}
+	excessBlobGas := eip4844.CalcExcessBlobGas(*parent.ExcessBlobGas, *parent.BlobGasUsed)
+	for i := uint64(0); i < lookAhead; i++ {
+		excessBlobGas = eip4844.CalcExcessBlobGas(excessBlobGas, params.MaxBlobGasPerBlock)
+	}
+	return eip4844.CalcBlobFee(excessBlobGas), nil
+}
+
+// 估算 BlobFeeCap：默认按最坏情况向后预估，历史模式下取历史 blob 基础费用的百分位，
+// 两种模式都不低于下一个区块的最低 blob 费用，并加上安全余量
+func EstimateBlobFeeCap(ctx context.Context, client FeeClient, cfg EstimateConfig) (*big.Int, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	parent, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest header: %w", err)
+	}
+
+	var fee *big.Int
+	if cfg.HistoryBlocks > 0 {
+		minFee, err := ProjectBlobFee(parent, 0)
+		if err != nil {
+			return nil, err
+		}
+		fee, err = HistoricalBlobFee(ctx, client, cfg.HistoryBlocks, cfg.Percentile)
+		if err != nil {
+			return nil, err
+		}
+		if fee.Cmp(minFee) < 0 {
+			fee = minFee
+		}
+	} else {
+		fee, err = ProjectBlobFee(parent, cfg.LookAhead)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return applyMargin(fee, cfg.MarginPct), nil
+}
+
+type feeHistoryResult struct {
+	OldestBlock       *hexutil.Big   `json:"oldestBlock"`
+	BaseFeePerBlobGas []*hexutil.Big `json:"baseFeePerBlobGas"`
+	BlobGasUsedRatio  []float64      `json:"blobGasUsedRatio"`
+}
+
+// 通过 eth_feeHistory 的 baseFeePerBlobGas 获取最近 blocks 个区块 blob 基础费用的百分位，
+// percentile 超出 0 到 100 时按边界处理
+func HistoricalBlobFee(ctx context.Context, client FeeClient, blocks uint64, percentile float64) (*big.Int, error) {
+	var res feeHistoryResult
+	if err := client.CallContext(ctx, &res, "eth_feeHistory", hexutil.Uint64(blocks), "latest", []float64{}); err != nil {
+		return nil, fmt.Errorf("failed to get fee history: %w", err)
+	}
+	if len(res.BaseFeePerBlobGas) == 0 {
+		return nil, ErrNoBlobFields
+	}
+
+	fees := make([]*big.Int, 0, len(res.BaseFeePerBlobGas))
+	for _, fee := range res.BaseFeePerBlobGas {
+		fees = append(fees, fee.ToInt())
+	}
+	return Percentile(fees, percentile), nil
+}
+
+// 返回 values 的第 p 百分位（最近秩法），values 为空时返回 0
+func Percentile(values []*big.Int, p float64) *big.Int {
+	if len(values) == 0 {
+		return new(big.Int)
+	}
+	sorted := make([]*big.Int, len(values))
+	copy(sorted, values)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Cmp(sorted[j]) < 0
+	})
+	return new(big.Int).Set(sorted[PercentileIndex(len(sorted), p)])
+}
+
+// 最近秩法下第 p 百分位在 n 个已排序元素中的下标，p 超出 0 到 100 时按边界处理，n 必须大于 0
+func PercentileIndex(n int, p float64) int {
+	idx := int(math.Ceil(p/100*float64(n))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= n {
+		idx = n - 1
+	}
+	return idx
+}
+
+func applyMargin(fee *big.Int, marginPct uint64) *big.Int {
+	withMargin := new(big.Int).Mul(fee, new(big.Int).SetUint64(100+marginPct))
+	withMargin.Div(withMargin, big.NewInt(100))
+	if withMargin.Cmp(fee) < 0 {
+		withMargin.Set(fee)
+	}
+	return withMargin
+}
@@ -0,0 +1,207 @@
+package blobFee
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func blobHeader(excess, used uint64) *types.Header {
+	return &types.Header{ExcessBlobGas: &excess, BlobGasUsed: &used}
+}
+
+func TestProjectBlobFee(t *testing.T) {
+	if _, err := ProjectBlobFee(&types.Header{}, 0); !errors.Is(err, ErrNoBlobFields) {
+		t.Fatalf("pre-Cancun header: err = %v, want ErrNoBlobFields", err)
+	}
+
+	cases := []struct {
+		name         string
+		excess, used uint64
+		lookAhead    uint64
+		wantExcess   uint64
+	}{
+		{"empty parent", 0, 0, 0, 0},
+		{"below target does not go negative", 100000, 0, 0, 0},
+		{"full parent", 0, params.MaxBlobGasPerBlock, 0, params.BlobTxTargetBlobGasPerBlock},
+		{"look ahead full blocks", 0, 0, 10, 10 * params.BlobTxTargetBlobGasPerBlock},
+		{"look ahead from high excess", 5000000, params.BlobTxTargetBlobGasPerBlock, 3, 5000000 + 3*params.BlobTxTargetBlobGasPerBlock},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fee, err := ProjectBlobFee(blobHeader(c.excess, c.used), c.lookAhead)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := eip4844.CalcBlobFee(c.wantExcess); fee.Cmp(want) != 0 {
+				t.Errorf("fee = %v, want %v", fee, want)
+			}
+		})
+	}
+
+	// 向后预估的区块越多，费用越高
+	near, _ := ProjectBlobFee(blobHeader(0, 0), 50)
+	far, _ := ProjectBlobFee(blobHeader(0, 0), 100)
+	if far.Cmp(near) <= 0 {
+		t.Errorf("fee after 100 blocks %v not above fee after 50 blocks %v", far, near)
+	}
+}
+
+func bigs(values ...int64) []*big.Int {
+	out := make([]*big.Int, len(values))
+	for i, v := range values {
+		out[i] = big.NewInt(v)
+	}
+	return out
+}
+
+func TestPercentile(t *testing.T) {
+	cases := []struct {
+		name   string
+		values []*big.Int
+		p      float64
+		want   int64
+	}{
+		{"empty", nil, 50, 0},
+		{"single p0", bigs(7), 0, 7},
+		{"single p50", bigs(7), 50, 7},
+		{"single p100", bigs(7), 100, 7},
+		{"p0 is min", bigs(5, 1, 3), 0, 1},
+		{"p100 is max", bigs(5, 1, 3), 100, 5},
+		{"p50 of ten", bigs(10, 9, 8, 7, 6, 5, 4, 3, 2, 1), 50, 5},
+		{"p90 of ten", bigs(10, 9, 8, 7, 6, 5, 4, 3, 2, 1), 90, 9},
+		{"p91 rounds up", bigs(10, 9, 8, 7, 6, 5, 4, 3, 2, 1), 91, 10},
+		{"above 100 clamps to max", bigs(1, 2, 3), 150, 3},
+		{"below 0 clamps to min", bigs(1, 2, 3), -5, 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Percentile(c.values, c.p); got.Int64() != c.want {
+				t.Errorf("Percentile = %v, want %d", got, c.want)
+			}
+		})
+	}
+
+	// 不修改输入，返回值与输入互不影响
+	values := bigs(3, 1, 2)
+	got := Percentile(values, 100)
+	got.SetInt64(100)
+	if values[0].Int64() != 3 || values[1].Int64() != 1 || values[2].Int64() != 2 {
+		t.Errorf("input modified: %v", values)
+	}
+}
+
+func TestApplyMargin(t *testing.T) {
+	cases := []struct {
+		fee       int64
+		marginPct uint64
+		want      int64
+	}{
+		{0, 50, 0},
+		{100, 0, 100},
+		{100, 10, 110},
+		{15, 10, 16}, // 16.5 向下取整
+		{1, 10, 1},
+		{100, 200, 300},
+	}
+	for _, c := range cases {
+		if got := applyMargin(big.NewInt(c.fee), c.marginPct); got.Int64() != c.want {
+			t.Errorf("applyMargin(%d, %d) = %v, want %d", c.fee, c.marginPct, got, c.want)
+		}
+	}
+}
+
+// 最新区块头为 head，eth_feeHistory 返回 fees 作为 baseFeePerBlobGas
+type fakeFeeClient struct {
+	head   *types.Header
+	fees   []int64
+	calls  int
+	method string
+	args   []interface{}
+}
+
+func (c *fakeFeeClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	c.calls++
+	return c.head, nil
+}
+
+func (c *fakeFeeClient) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	c.calls++
+	c.method, c.args = method, args
+	res := result.(*feeHistoryResult)
+	for _, fee := range c.fees {
+		res.BaseFeePerBlobGas = append(res.BaseFeePerBlobGas, (*hexutil.Big)(big.NewInt(fee)))
+	}
+	return nil
+}
+
+func TestEstimateBlobFeeCap(t *testing.T) {
+	highExcess := uint64(10_000_000)
+	minFee := eip4844.CalcBlobFee(eip4844.CalcExcessBlobGas(highExcess, 0)).Int64()
+	cases := []struct {
+		name   string
+		head   *types.Header
+		fees   []int64
+		cfg    EstimateConfig
+		want   *big.Int
+		wantFn string // 期望调用的 RPC 方法
+	}{
+		{"projection", blobHeader(0, params.MaxBlobGasPerBlock), nil, EstimateConfig{LookAhead: 2, MarginPct: 10},
+			applyMargin(mustProject(t, blobHeader(0, params.MaxBlobGasPerBlock), 2), 10), ""},
+		{"history percentile", blobHeader(0, 0), []int64{10, 2, 8, 4, 6}, EstimateConfig{HistoryBlocks: 5, Percentile: 80, MarginPct: 50},
+			big.NewInt(12), "eth_feeHistory"},
+		{"history below next block fee", blobHeader(highExcess, 0), []int64{1, 2, 3}, EstimateConfig{HistoryBlocks: 3, Percentile: 50},
+			big.NewInt(minFee), "eth_feeHistory"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := &fakeFeeClient{head: c.head, fees: c.fees}
+			got, err := EstimateBlobFeeCap(context.Background(), client, c.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Cmp(c.want) != 0 {
+				t.Errorf("fee cap = %v, want %v", got, c.want)
+			}
+			if client.method != c.wantFn {
+				t.Errorf("called %q, want %q", client.method, c.wantFn)
+			}
+			if c.wantFn != "" && client.args[0] != hexutil.Uint64(c.cfg.HistoryBlocks) {
+				t.Errorf("eth_feeHistory block count = %v, want %d", client.args[0], c.cfg.HistoryBlocks)
+			}
+		})
+	}
+}
+
+func TestEstimateBlobFeeCapErrors(t *testing.T) {
+	client := &fakeFeeClient{head: blobHeader(0, 0)}
+	for _, p := range []float64{-1, 100.5} {
+		if _, err := EstimateBlobFeeCap(context.Background(), client, EstimateConfig{HistoryBlocks: 5, Percentile: p}); err == nil {
+			t.Errorf("percentile %v: expected error", p)
+		}
+	}
+	if client.calls != 0 {
+		t.Errorf("invalid config made %d calls", client.calls)
+	}
+
+	if _, err := EstimateBlobFeeCap(context.Background(), &fakeFeeClient{head: &types.Header{}}, EstimateConfig{}); !errors.Is(err, ErrNoBlobFields) {
+		t.Errorf("pre-Cancun head: err = %v, want ErrNoBlobFields", err)
+	}
+	if _, err := EstimateBlobFeeCap(context.Background(), client, EstimateConfig{HistoryBlocks: 5, Percentile: 50}); !errors.Is(err, ErrNoBlobFields) {
+		t.Errorf("empty fee history: err = %v, want ErrNoBlobFields", err)
+	}
+}
+
+func mustProject(t *testing.T, parent *types.Header, lookAhead uint64) *big.Int {
+	fee, err := ProjectBlobFee(parent, lookAhead)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fee
+}
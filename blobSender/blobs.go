@@ -0,0 +1,77 @@
+package blobSender
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const (
+	// 每笔交易最多可携带的 blob 数量
+	MaxBlobsPerTx = params.MaxBlobGasPerBlock / params.BlobTxBlobGasPerBlob
+
+	fieldElementsPerBlob = 4096
+	// 每个 field element 首字节置 0 保证小于 BLS 模数，剩余 31 字节存放数据
+	usableBytesPerElement = 31
+	BytesPerBlob          = fieldElementsPerBlob * usableBytesPerElement
+)
+
+// 将数据按每个 field element 31 字节编码进 blob，不足部分补 0
+func EncodeBlobs(data []byte) []kzg4844.Blob {
+	var blobs []kzg4844.Blob
+	for len(data) > 0 {
+		var blob kzg4844.Blob
+		for i := 0; i < fieldElementsPerBlob && len(data) > 0; i++ {
+			n := copy(blob[i*32+1:(i+1)*32], data)
+			data = data[n:]
+		}
+		blobs = append(blobs, blob)
+	}
+	return blobs
+}
+
+func MakeSidecar(blobs []kzg4844.Blob) (*types.BlobTxSidecar, error) {
+	var (
+		commitments []kzg4844.Commitment
+		proofs      []kzg4844.Proof
+	)
+
+	for i, blob := range blobs {
+		c, err := kzg4844.BlobToCommitment(&blob)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute commitment for blob %d: %w", i, err)
+		}
+		p, err := kzg4844.ComputeBlobProof(&blob, c)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute proof for blob %d: %w", i, err)
+		}
+
+		commitments = append(commitments, c)
+		proofs = append(proofs, p)
+	}
+
+	return &types.BlobTxSidecar{
+		Blobs:       blobs,
+		Commitments: commitments,
+		Proofs:      proofs,
+	}, nil
+}
+
+// 将 blob 按每笔交易最多 perTx 个拆分
+func SplitBlobs(blobs []kzg4844.Blob, perTx int) [][]kzg4844.Blob {
+	if perTx <= 0 || perTx > MaxBlobsPerTx {
+		perTx = MaxBlobsPerTx
+	}
+	var batches [][]kzg4844.Blob
+	for len(blobs) > 0 {
+		n := perTx
+		if n > len(blobs) {
+			n = len(blobs)
+		}
+		batches = append(batches, blobs[:n])
+		blobs = blobs[n:]
+	}
+	return batches
+}
@@ -0,0 +1,101 @@
+package blobSender
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"test/blobTx/blobData"
+)
+
+// 不含 0 的数据，避免 DecodeBlob 去掉末尾的 0 后无法比较
+func testPayload(n int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(i%255) + 1
+	}
+	return data
+}
+
+func TestEncodeBlobsRoundTrip(t *testing.T) {
+	cases := []struct {
+		name      string
+		size      int
+		wantBlobs int
+	}{
+		{"empty", 0, 0},
+		{"one byte", 1, 1},
+		{"one field element", usableBytesPerElement, 1},
+		{"one field element plus one", usableBytesPerElement + 1, 1},
+		{"two field elements", 2 * usableBytesPerElement, 1},
+		{"full blob", BytesPerBlob, 1},
+		{"full blob plus one", BytesPerBlob + 1, 2},
+		{"two full blobs", 2 * BytesPerBlob, 2},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data := testPayload(c.size)
+			blobs := EncodeBlobs(data)
+			if len(blobs) != c.wantBlobs {
+				t.Fatalf("got %d blobs, want %d", len(blobs), c.wantBlobs)
+			}
+
+			var decoded []byte
+			for i, blob := range blobs {
+				// 每个 field element 的首字节必须为 0，保证小于 BLS 模数
+				for j := 0; j < len(blob); j += 32 {
+					if blob[j] != 0 {
+						t.Fatalf("blob %d field element %d has non-zero high byte", i, j/32)
+					}
+				}
+				decoded = append(decoded, blobData.DecodeBlob(blob)...)
+			}
+			if !bytes.Equal(decoded, data) {
+				t.Errorf("round trip mismatch: got %d bytes, want %d", len(decoded), len(data))
+			}
+		})
+	}
+}
+
+func TestSplitBlobs(t *testing.T) {
+	cases := []struct {
+		name  string
+		n     int
+		perTx int
+		want  []int
+	}{
+		{"no blobs", 0, 2, nil},
+		{"one blob", 1, 2, []int{1}},
+		{"max blobs", MaxBlobsPerTx, MaxBlobsPerTx, []int{MaxBlobsPerTx}},
+		{"max blobs plus one", MaxBlobsPerTx + 1, MaxBlobsPerTx, []int{MaxBlobsPerTx, 1}},
+		{"uneven split", 5, 2, []int{2, 2, 1}},
+		{"perTx zero uses max", MaxBlobsPerTx + 1, 0, []int{MaxBlobsPerTx, 1}},
+		{"perTx above max uses max", MaxBlobsPerTx + 1, MaxBlobsPerTx + 1, []int{MaxBlobsPerTx, 1}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			blobs := make([]kzg4844.Blob, c.n)
+			for i := range blobs {
+				blobs[i][1] = byte(i)
+			}
+			batches := SplitBlobs(blobs, c.perTx)
+			if len(batches) != len(c.want) {
+				t.Fatalf("got %d batches, want %d", len(batches), len(c.want))
+			}
+			next := 0
+			for i, batch := range batches {
+				if len(batch) != c.want[i] {
+					t.Errorf("batch %d has %d blobs, want %d", i, len(batch), c.want[i])
+				}
+				// 拆分后保持原有顺序
+				for _, blob := range batch {
+					if blob[1] != byte(next) {
+						t.Errorf("batch %d out of order", i)
+					}
+					next++
+				}
+			}
+		})
+	}
+}
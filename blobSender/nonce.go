@@ -0,0 +1,22 @@
+package blobSender
+
+import "sync"
+
+// 本地管理 nonce，只在开始时从节点获取一次，避免批量发送时重复查询 PendingNonceAt
+type NonceManager struct {
+	mu   sync.Mutex
+	next uint64
+}
+
+func NewNonceManager(start uint64) *NonceManager {
+	return &NonceManager{next: start}
+}
+
+func (m *NonceManager) Next() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	nonce := m.next
+	m.next++
+	return nonce
+}
@@ -0,0 +1,117 @@
+package blobSender
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// 已签名交易的摘要，供广播前人工审核
+type TxSummary struct {
+	Hash       common.Hash     `json:"hash"`
+	Type       uint8           `json:"type"`
+	ChainID    *big.Int        `json:"chainId"`
+	From       common.Address  `json:"from"`
+	To         *common.Address `json:"to"`
+	Nonce      uint64          `json:"nonce"`
+	Gas        uint64          `json:"gas"`
+	GasTipCap  *big.Int        `json:"gasTipCap"`
+	GasFeeCap  *big.Int        `json:"gasFeeCap"`
+	BlobFeeCap *big.Int        `json:"blobFeeCap"`
+	Value      *big.Int        `json:"value"`
+	BlobCount  int             `json:"blobCount"`
+	BlobHashes []common.Hash   `json:"blobHashes"`
+	RawSize    int             `json:"rawSize"`
+}
+
+func Summarize(tx *types.Transaction) (*TxSummary, error) {
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover sender: %w", err)
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &TxSummary{
+		Hash:       tx.Hash(),
+		Type:       tx.Type(),
+		ChainID:    tx.ChainId(),
+		From:       from,
+		To:         tx.To(),
+		Nonce:      tx.Nonce(),
+		Gas:        tx.Gas(),
+		GasTipCap:  tx.GasTipCap(),
+		GasFeeCap:  tx.GasFeeCap(),
+		BlobFeeCap: tx.BlobGasFeeCap(),
+		Value:      tx.Value(),
+		BlobCount:  len(tx.BlobHashes()),
+		BlobHashes: tx.BlobHashes(),
+		RawSize:    len(raw),
+	}, nil
+}
+
+// 将已签名交易以网络格式（交易 + sidecar）的 RLP 十六进制写入 rlpPath，并将摘要写入 summaryPath
+func WriteSignedTx(tx *types.Transaction, rlpPath, summaryPath string) error {
+	if tx.Type() == types.BlobTxType && tx.BlobTxSidecar() == nil {
+		return fmt.Errorf("blob tx %s has no sidecar", tx.Hash().Hex())
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(rlpPath, []byte(hexutil.Encode(raw)), 0644); err != nil {
+		return err
+	}
+
+	summary, err := Summarize(tx)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(summaryPath, data, 0644)
+}
+
+// 读取 WriteSignedTx 写入的文件，同时支持十六进制和二进制 RLP
+func ReadSignedTx(path string) ([]byte, *types.Transaction, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	raw := data
+	if text := strings.TrimSpace(string(data)); strings.HasPrefix(text, "0x") {
+		if raw, err = hexutil.Decode(text); err != nil {
+			return nil, nil, fmt.Errorf("invalid hex in %s: %w", path, err)
+		}
+	}
+
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, nil, fmt.Errorf("invalid transaction in %s: %w", path, err)
+	}
+	return raw, tx, nil
+}
+
+// 发送原始 JSON-RPC 请求的接口，*rpc.Client 满足该接口
+type RPCCaller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// 通过 eth_sendRawTransaction 原样广播已签名交易
+func BroadcastRawTx(ctx context.Context, client RPCCaller, raw []byte) (common.Hash, error) {
+	var hash common.Hash
+	if err := client.CallContext(ctx, &hash, "eth_sendRawTransaction", hexutil.Encode(raw)); err != nil {
+		return common.Hash{}, err
+	}
+	return hash, nil
+}
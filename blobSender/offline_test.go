@@ -0,0 +1,133 @@
+package blobSender
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+var testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313e0ac59c7ba1c6f2a3")
+
+func signedBlobTx(t *testing.T) *types.Transaction {
+	sidecar, err := MakeSidecar(EncodeBlobs([]byte("offline signing")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	to := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	tx, err := types.SignNewTx(testKey, types.NewCancunSigner(common.Big1), &types.BlobTx{
+		ChainID:    uint256.NewInt(1),
+		Nonce:      7,
+		GasTipCap:  uint256.NewInt(1e9),
+		GasFeeCap:  uint256.NewInt(10e9),
+		Gas:        21000,
+		To:         to,
+		BlobFeeCap: uint256.NewInt(3e9),
+		BlobHashes: sidecar.BlobHashes(),
+		Sidecar:    sidecar,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestSignedTxRoundTrip(t *testing.T) {
+	tx := signedBlobTx(t)
+	want, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+
+	hexPath := filepath.Join(dir, "tx.rlp")
+	if err := WriteSignedTx(tx, hexPath, filepath.Join(dir, "tx.json")); err != nil {
+		t.Fatal(err)
+	}
+	binPath := filepath.Join(dir, "tx.bin")
+	if err := os.WriteFile(binPath, want, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{hexPath, binPath} {
+		raw, got, err := ReadSignedTx(path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if !bytes.Equal(raw, want) || got.Hash() != tx.Hash() {
+			t.Errorf("%s: read tx %s, want %s", path, got.Hash().Hex(), tx.Hash().Hex())
+		}
+		if got.BlobTxSidecar() == nil || len(got.BlobTxSidecar().Blobs) != len(tx.BlobTxSidecar().Blobs) {
+			t.Errorf("%s: sidecar not preserved", path)
+		}
+	}
+}
+
+func TestWriteSignedTxRequiresSidecar(t *testing.T) {
+	dir := t.TempDir()
+	rlpPath := filepath.Join(dir, "tx.rlp")
+	if err := WriteSignedTx(signedBlobTx(t).WithoutBlobTxSidecar(), rlpPath, filepath.Join(dir, "tx.json")); err == nil {
+		t.Fatal("expected error for blob tx without sidecar")
+	}
+	if _, err := os.Stat(rlpPath); !os.IsNotExist(err) {
+		t.Errorf("%s should not be written: %v", rlpPath, err)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	tx := signedBlobTx(t)
+	dir := t.TempDir()
+	summaryPath := filepath.Join(dir, "tx.json")
+	if err := WriteSignedTx(tx, filepath.Join(dir, "tx.rlp"), summaryPath); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(summaryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var summary TxSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, _ := tx.MarshalBinary()
+	if summary.Hash != tx.Hash() || summary.Type != types.BlobTxType || summary.ChainID.Uint64() != 1 ||
+		summary.From != crypto.PubkeyToAddress(testKey.PublicKey) || *summary.To != *tx.To() ||
+		summary.Nonce != 7 || summary.Gas != 21000 || summary.GasTipCap.Uint64() != 1e9 ||
+		summary.GasFeeCap.Uint64() != 10e9 || summary.BlobFeeCap.Uint64() != 3e9 || summary.Value.Sign() != 0 ||
+		summary.BlobCount != 1 || summary.BlobHashes[0] != tx.BlobHashes()[0] || summary.RawSize != len(raw) {
+		t.Errorf("unexpected summary: %s", data)
+	}
+}
+
+type fakeCaller struct {
+	method string
+	args   []interface{}
+}
+
+func (c *fakeCaller) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	c.method, c.args = method, args
+	*result.(*common.Hash) = common.HexToHash("0x01")
+	return nil
+}
+
+func TestBroadcastRawTx(t *testing.T) {
+	caller := new(fakeCaller)
+	raw := []byte{0x03, 0xaa}
+	hash, err := BroadcastRawTx(context.Background(), caller, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != common.HexToHash("0x01") || caller.method != "eth_sendRawTransaction" ||
+		len(caller.args) != 1 || caller.args[0] != hexutil.Encode(raw) {
+		t.Errorf("BroadcastRawTx = %s, call %s %v", hash.Hex(), caller.method, caller.args)
+	}
+}
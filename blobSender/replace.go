@@ -0,0 +1,244 @@
+package blobSender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/holiman/uint256"
+)
+
+// blobpool 的替换规则：GasTipCap、GasFeeCap、BlobFeeCap 都必须至少提高 PriceBump%
+const PriceBump = 100
+
+var ErrFeeCeiling = errors.New("bumped fee exceeds configured ceiling")
+
+type ReplaceConfig struct {
+	WaitBlocks    uint64        // 每次发送后等待打包的区块数
+	MaxGasFeeCap  *uint256.Int  // GasFeeCap 上限，nil 表示不限制
+	MaxBlobFeeCap *uint256.Int  // BlobFeeCap 上限，nil 表示不限制
+	PollInterval  time.Duration // 查询回执的间隔
+}
+
+type SignFn func(tx *types.Transaction) (*types.Transaction, error)
+
+// 发送和跟踪交易需要的节点接口，*ethclient.Client 满足该接口
+type Client interface {
+	ethereum.BlockNumberReader
+	ethereum.TransactionSender
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// 按 blobpool 的替换规则提高三个费用字段，sidecar 等其他字段保持不变
+func BumpFees(tx *types.BlobTx, cfg ReplaceConfig) (*types.BlobTx, error) {
+	bumped := *tx
+	bumped.GasTipCap = bumpFee(tx.GasTipCap, PriceBump)
+	bumped.GasFeeCap = bumpFee(tx.GasFeeCap, PriceBump)
+	bumped.BlobFeeCap = bumpFee(tx.BlobFeeCap, PriceBump)
+	if err := CheckFees(&bumped, cfg); err != nil {
+		return nil, err
+	}
+	return &bumped, nil
+}
+
+// 检查 GasTipCap 不超过 GasFeeCap，且 GasFeeCap、BlobFeeCap 不超过配置的上限
+func CheckFees(tx *types.BlobTx, cfg ReplaceConfig) error {
+	if tx.GasTipCap != nil && tx.GasFeeCap != nil && tx.GasTipCap.Gt(tx.GasFeeCap) {
+		return fmt.Errorf("gas tip cap %v > gas fee cap %v", tx.GasTipCap, tx.GasFeeCap)
+	}
+	if cfg.MaxGasFeeCap != nil && tx.GasFeeCap != nil && tx.GasFeeCap.Gt(cfg.MaxGasFeeCap) {
+		return fmt.Errorf("%w: gas fee cap %v > %v", ErrFeeCeiling, tx.GasFeeCap, cfg.MaxGasFeeCap)
+	}
+	if cfg.MaxBlobFeeCap != nil && tx.BlobFeeCap != nil && tx.BlobFeeCap.Gt(cfg.MaxBlobFeeCap) {
+		return fmt.Errorf("%w: blob fee cap %v > %v", ErrFeeCeiling, tx.BlobFeeCap, cfg.MaxBlobFeeCap)
+	}
+	return nil
+}
+
+// 将费用提高 pct%，向上取整，且严格大于原费用（交易池要求替换交易的费用必须更高）
+func bumpFee(fee *uint256.Int, pct uint64) *uint256.Int {
+	if fee == nil || fee.IsZero() {
+		return uint256.NewInt(1)
+	}
+	bumped := new(uint256.Int).Mul(fee, uint256.NewInt(100+pct))
+	bumped.AddUint64(bumped, 99)
+	bumped.Div(bumped, uint256.NewInt(100))
+	if bumped.Cmp(fee) <= 0 {
+		bumped.AddUint64(fee, 1)
+	}
+	return bumped
+}
+
+// 发送 blob 交易，若 WaitBlocks 个区块内未被打包，则用相同 nonce 提高费用后重新签名发送，
+// 直到任意一个版本被打包或费用超过上限
+func SendWithReplacement(ctx context.Context, client Client, sign SignFn, inner *types.BlobTx, cfg ReplaceConfig) (*types.Receipt, error) {
+	receipts, err := SendBatch(ctx, client, sign, []*types.BlobTx{inner}, cfg)
+	return receipts[0], err
+}
+
+// 一笔交易的所有已发送版本
+type pendingTx struct {
+	inner   *types.BlobTx
+	sent    []common.Hash
+	receipt *types.Receipt
+	capped  error // 再次提高费用会超过上限，不再替换，只等待已发送的版本
+}
+
+// 按 nonce 顺序发送一批 blob 交易并跟踪到全部打包，未及时打包的交易按替换规则提高费用重新发送。
+// 返回的回执与 txs 一一对应，出错时未打包的交易对应 nil
+func SendBatch(ctx context.Context, client Client, sign SignFn, txs []*types.BlobTx, cfg ReplaceConfig) ([]*types.Receipt, error) {
+	pending := make([]*pendingTx, len(txs))
+	for i, tx := range txs {
+		if err := CheckFees(tx, cfg); err != nil {
+			return make([]*types.Receipt, len(txs)), fmt.Errorf("blob tx with nonce %d: %w", tx.Nonce, err)
+		}
+		pending[i] = &pendingTx{inner: tx}
+	}
+
+	for {
+		for _, p := range pending {
+			if p.receipt != nil || p.capped != nil {
+				continue
+			}
+			if err := p.send(ctx, client, sign); err != nil {
+				return collectReceipts(pending), err
+			}
+		}
+
+		head, err := client.BlockNumber(ctx)
+		if err != nil {
+			return collectReceipts(pending), fmt.Errorf("failed to get block number: %w", err)
+		}
+		done, err := waitIncluded(ctx, client, pending, head+cfg.WaitBlocks, cfg.PollInterval)
+		if err != nil || done {
+			return collectReceipts(pending), err
+		}
+
+		// 达到上限的交易已经多等待了一轮仍未打包
+		for _, p := range pending {
+			if p.receipt == nil && p.capped != nil {
+				return collectReceipts(pending), p.capped
+			}
+		}
+
+		for _, p := range pending {
+			if p.receipt != nil {
+				continue
+			}
+			next, err := BumpFees(p.inner, cfg)
+			if errors.Is(err, ErrFeeCeiling) {
+				// 最后发送的版本仍可能被打包，继续等待一轮后再报告
+				log.Printf("Blob tx with nonce %d not included within %d blocks and fees are at the ceiling, waiting for the last version", p.inner.Nonce, cfg.WaitBlocks)
+				p.capped = fmt.Errorf("blob tx with nonce %d: %w", p.inner.Nonce, err)
+				continue
+			}
+			if err != nil {
+				return collectReceipts(pending), err
+			}
+			log.Printf("Blob tx with nonce %d not included within %d blocks, replacing with bumped fees", p.inner.Nonce, cfg.WaitBlocks)
+			p.inner = next
+		}
+	}
+}
+
+func (p *pendingTx) send(ctx context.Context, client Client, sign SignFn) error {
+	signedTx, err := sign(types.NewTx(p.inner))
+	if err != nil {
+		return fmt.Errorf("failed to sign the transaction: %w", err)
+	}
+
+	if err := client.SendTransaction(ctx, signedTx); err != nil {
+		// 之前发送的版本可能已经被打包
+		if receipt, rerr := findReceipt(ctx, client, p.sent); rerr == nil && receipt != nil {
+			p.receipt = receipt
+			return nil
+		}
+		return fmt.Errorf("failed to send the transaction with nonce %d: %w", p.inner.Nonce, err)
+	}
+	p.sent = append(p.sent, signedTx.Hash())
+	log.Printf("Sent blob tx %s (nonce %d, blobs %d, tipCap %v, feeCap %v, blobFeeCap %v)", signedTx.Hash().Hex(), p.inner.Nonce, len(p.inner.BlobHashes), p.inner.GasTipCap, p.inner.GasFeeCap, p.inner.BlobFeeCap)
+	return nil
+}
+
+func collectReceipts(pending []*pendingTx) []*types.Receipt {
+	receipts := make([]*types.Receipt, len(pending))
+	for i, p := range pending {
+		receipts[i] = p.receipt
+	}
+	return receipts
+}
+
+// 等待所有交易的任意一个版本被打包，直到区块高度超过 deadline，返回是否全部打包
+func waitIncluded(ctx context.Context, client Client, pending []*pendingTx, deadline uint64, interval time.Duration) (bool, error) {
+	for {
+		done := true
+		for _, p := range pending {
+			if p.receipt != nil {
+				continue
+			}
+			receipt, err := findReceipt(ctx, client, p.sent)
+			if err != nil {
+				return false, err
+			}
+			if receipt == nil {
+				done = false
+				continue
+			}
+			p.receipt = receipt
+			log.Printf("Blob tx %s included in block %v", receipt.TxHash.Hex(), receipt.BlockNumber)
+		}
+		if done {
+			return true, nil
+		}
+
+		head, err := client.BlockNumber(ctx)
+		if err != nil {
+			return false, fmt.Errorf("failed to get block number: %w", err)
+		}
+		if head >= deadline {
+			return false, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// 返回 hashes 中任意一个已打包版本的回执，都未打包时返回 nil
+func findReceipt(ctx context.Context, client Client, hashes []common.Hash) (*types.Receipt, error) {
+	for _, hash := range hashes {
+		receipt, err := client.TransactionReceipt(ctx, hash)
+		if err == nil {
+			return receipt, nil
+		}
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if isTransientLookupError(err) {
+			log.Printf("Receipt lookup for tx %s failed, treating it as not yet included: %v", hash.Hex(), err)
+			continue
+		}
+		return nil, fmt.Errorf("failed to get receipt for tx %s: %w", hash.Hex(), err)
+	}
+	return nil, nil
+}
+
+// 节点返回的 JSON-RPC 错误（例如交易索引尚未建立完成）和网络超时只说明暂时查不到回执
+func isTransientLookupError(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,219 @@
+package blobSender
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
+)
+
+// 节点在交易索引建立完成前返回的 JSON-RPC 错误
+type indexingError struct{}
+
+func (indexingError) Error() string  { return "transaction indexing is in progress" }
+func (indexingError) ErrorCode() int { return -32000 }
+
+var errIndexing error = indexingError{}
+
+// 每次查询区块高度时前进一个区块，前 failures 次查询回执返回 err
+type fakeClient struct {
+	head     uint64
+	failures int
+	err      error
+	receipt  *types.Receipt
+	sent     int
+}
+
+func (c *fakeClient) BlockNumber(ctx context.Context) (uint64, error) {
+	c.head++
+	return c.head, nil
+}
+
+func (c *fakeClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	c.sent++
+	return nil
+}
+
+func (c *fakeClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if c.failures > 0 {
+		c.failures--
+		return nil, c.err
+	}
+	if c.receipt == nil {
+		return nil, ethereum.NotFound
+	}
+	return c.receipt, nil
+}
+
+var errLookup = errors.New("lookup failed")
+
+func TestWaitIncluded(t *testing.T) {
+	receipt := &types.Receipt{TxHash: common.HexToHash("0x01"), BlockNumber: big.NewInt(3)}
+	cases := []struct {
+		name     string
+		client   *fakeClient
+		wantDone bool
+		wantErr  error
+	}{
+		{"included", &fakeClient{receipt: receipt}, true, nil},
+		{"not included before deadline", &fakeClient{}, false, nil},
+		{"lookup errors then included", &fakeClient{failures: 2, err: errIndexing, receipt: receipt}, true, nil},
+		{"lookup errors until deadline", &fakeClient{failures: 100, err: errIndexing, receipt: receipt}, false, nil},
+		{"lookup fails", &fakeClient{failures: 1, err: errLookup, receipt: receipt}, false, errLookup},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pending := []*pendingTx{{sent: []common.Hash{receipt.TxHash}}}
+			done, err := waitIncluded(context.Background(), c.client, pending, 5, 0)
+			if done != c.wantDone || !errors.Is(err, c.wantErr) || (c.wantErr == nil && err != nil) {
+				t.Fatalf("waitIncluded = %v, %v; want %v, %v", done, err, c.wantDone, c.wantErr)
+			}
+			if done && pending[0].receipt != receipt {
+				t.Errorf("receipt not recorded")
+			}
+		})
+	}
+}
+
+func TestBumpFee(t *testing.T) {
+	cases := []struct {
+		fee  *uint256.Int
+		pct  uint64
+		want uint64
+	}{
+		{nil, 100, 1},
+		{uint256.NewInt(0), 100, 1},
+		{uint256.NewInt(1), 100, 2},
+		{uint256.NewInt(7), 100, 14},
+		{uint256.NewInt(1000000000), 100, 2000000000},
+		{uint256.NewInt(0), 10, 1},
+		{uint256.NewInt(1), 10, 2},   // 1.1 向上取整
+		{uint256.NewInt(5), 10, 6},   // 5.5 向上取整
+		{uint256.NewInt(15), 10, 17}, // 16.5 向上取整
+		{uint256.NewInt(100), 10, 110},
+		{uint256.NewInt(5), 0, 6}, // 不提高时也必须严格大于原费用
+	}
+	for _, c := range cases {
+		if got := bumpFee(c.fee, c.pct); got.Uint64() != c.want {
+			t.Errorf("bumpFee(%v, %d) = %v, want %d", c.fee, c.pct, got, c.want)
+		}
+	}
+}
+
+func TestBumpFees(t *testing.T) {
+	gwei := func(n uint64) *uint256.Int { return uint256.NewInt(n * 1e9) }
+	cases := []struct {
+		name                       string
+		tip, feeCap, blobFeeCap    *uint256.Int
+		maxFeeCap, maxBlobFeeCap   *uint256.Int
+		wantTip, wantFee, wantBlob *uint256.Int
+		wantErr                    error
+	}{
+		{name: "no ceilings", tip: gwei(1), feeCap: gwei(10), blobFeeCap: gwei(3),
+			wantTip: gwei(2), wantFee: gwei(20), wantBlob: gwei(6)},
+		{name: "at ceilings", tip: gwei(1), feeCap: gwei(10), blobFeeCap: gwei(3), maxFeeCap: gwei(20), maxBlobFeeCap: gwei(6),
+			wantTip: gwei(2), wantFee: gwei(20), wantBlob: gwei(6)},
+		{name: "gas fee cap over ceiling", tip: gwei(1), feeCap: gwei(10), blobFeeCap: gwei(3), maxFeeCap: gwei(19),
+			wantErr: ErrFeeCeiling},
+		{name: "blob fee cap over ceiling", tip: gwei(1), feeCap: gwei(10), blobFeeCap: gwei(3), maxBlobFeeCap: gwei(5),
+			wantErr: ErrFeeCeiling},
+		{name: "already over ceiling", tip: gwei(1), feeCap: gwei(30), blobFeeCap: gwei(3), maxFeeCap: gwei(20),
+			wantErr: ErrFeeCeiling},
+		{name: "zero fees", tip: uint256.NewInt(0), feeCap: uint256.NewInt(0), blobFeeCap: uint256.NewInt(0),
+			wantTip: uint256.NewInt(1), wantFee: uint256.NewInt(1), wantBlob: uint256.NewInt(1)},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tx := &types.BlobTx{Nonce: 3, GasTipCap: c.tip, GasFeeCap: c.feeCap, BlobFeeCap: c.blobFeeCap}
+			bumped, err := BumpFees(tx, ReplaceConfig{MaxGasFeeCap: c.maxFeeCap, MaxBlobFeeCap: c.maxBlobFeeCap})
+			if c.wantErr != nil {
+				if !errors.Is(err, c.wantErr) {
+					t.Fatalf("err = %v, want %v", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bumped.GasTipCap.Eq(c.wantTip) || !bumped.GasFeeCap.Eq(c.wantFee) || !bumped.BlobFeeCap.Eq(c.wantBlob) {
+				t.Errorf("bumped fees = %v/%v/%v, want %v/%v/%v", bumped.GasTipCap, bumped.GasFeeCap, bumped.BlobFeeCap, c.wantTip, c.wantFee, c.wantBlob)
+			}
+			if bumped.Nonce != tx.Nonce || tx.GasFeeCap != c.feeCap {
+				t.Errorf("BumpFees changed nonce or modified the original tx")
+			}
+		})
+	}
+}
+
+func TestCheckFees(t *testing.T) {
+	cfg := ReplaceConfig{MaxGasFeeCap: uint256.NewInt(100), MaxBlobFeeCap: uint256.NewInt(50)}
+	cases := []struct {
+		name                    string
+		tip, feeCap, blobFeeCap uint64
+		wantErr                 bool
+	}{
+		{"within ceilings", 10, 100, 50, false},
+		{"tip above fee cap", 20, 10, 1, true},
+		{"fee cap above ceiling", 1, 101, 1, true},
+		{"blob fee cap above ceiling", 1, 10, 51, true},
+	}
+	for _, c := range cases {
+		tx := &types.BlobTx{GasTipCap: uint256.NewInt(c.tip), GasFeeCap: uint256.NewInt(c.feeCap), BlobFeeCap: uint256.NewInt(c.blobFeeCap)}
+		if err := CheckFees(tx, cfg); (err != nil) != c.wantErr {
+			t.Errorf("%s: CheckFees = %v, want error %v", c.name, err, c.wantErr)
+		}
+	}
+}
+
+func TestSendBatchRejectsFeesOverCeiling(t *testing.T) {
+	client := &fakeClient{}
+	txs := []*types.BlobTx{{Nonce: 1, GasTipCap: uint256.NewInt(1), GasFeeCap: uint256.NewInt(200), BlobFeeCap: uint256.NewInt(1)}}
+	sign := func(tx *types.Transaction) (*types.Transaction, error) {
+		t.Fatal("tx over the ceiling must not be signed")
+		return nil, nil
+	}
+	receipts, err := SendBatch(context.Background(), client, sign, txs, ReplaceConfig{MaxGasFeeCap: uint256.NewInt(100)})
+	if !errors.Is(err, ErrFeeCeiling) || len(receipts) != 1 || receipts[0] != nil {
+		t.Fatalf("SendBatch = %v, %v", receipts, err)
+	}
+}
+
+func TestSendBatchWaitsAtCeiling(t *testing.T) {
+	receipt := &types.Receipt{TxHash: common.HexToHash("0x01"), BlockNumber: big.NewInt(5)}
+	cases := []struct {
+		name     string
+		failures int // 回执在第 failures+1 次查询时可见
+		wantErr  error
+	}{
+		{"included after reaching the ceiling", 3, nil},
+		{"never included", 100, ErrFeeCeiling},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := &fakeClient{failures: c.failures, err: ethereum.NotFound, receipt: receipt}
+			sign := func(tx *types.Transaction) (*types.Transaction, error) { return tx, nil }
+			// 第一次提高费用后 GasFeeCap 为 200，超过上限
+			txs := []*types.BlobTx{{Nonce: 1, GasTipCap: uint256.NewInt(1), GasFeeCap: uint256.NewInt(100), BlobFeeCap: uint256.NewInt(1)}}
+			cfg := ReplaceConfig{WaitBlocks: 2, MaxGasFeeCap: uint256.NewInt(100)}
+			receipts, err := SendBatch(context.Background(), client, sign, txs, cfg)
+			if !errors.Is(err, c.wantErr) || (c.wantErr == nil && err != nil) {
+				t.Fatalf("err = %v, want %v", err, c.wantErr)
+			}
+			var want *types.Receipt
+			if c.wantErr == nil {
+				want = receipt
+			}
+			if receipts[0] != want {
+				t.Errorf("receipt = %v, want %v", receipts[0], want)
+			}
+			if client.sent != 1 {
+				t.Errorf("sent %d times, want 1", client.sent)
+			}
+		})
+	}
+}
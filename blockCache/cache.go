@@ -0,0 +1,372 @@
+package blockCache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var ErrNotCached = errors.New("not in cache")
+
+// 已确定区块和回执的本地缓存，按链 ID 分目录：
+//
+//	<chainID>/blocks/<hash>.rlp              区块 RLP
+//	<chainID>/receipts/<blockHash>/<tx>.json 该区块内交易的回执
+//	<chainID>/numbers.jsonl                  区块号到区块哈希的索引
+//
+// 读取时会更新文件的修改时间，Prune 按修改时间从旧到新淘汰
+type Cache struct {
+	dir string
+
+	mu      sync.Mutex
+	numbers map[uint64]map[uint64]common.Hash // chainID -> number -> hash
+}
+
+type numberEntry struct {
+	Number uint64      `json:"number"`
+	Hash   common.Hash `json:"hash"`
+}
+
+func Open(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Cache{dir: dir, numbers: make(map[uint64]map[uint64]common.Hash)}, nil
+}
+
+func (c *Cache) chainDir(chainID uint64) string {
+	return filepath.Join(c.dir, strconv.FormatUint(chainID, 10))
+}
+
+func (c *Cache) blockPath(chainID uint64, hash common.Hash) string {
+	return filepath.Join(c.chainDir(chainID), "blocks", hash.Hex()+".rlp")
+}
+
+func (c *Cache) receiptPath(chainID uint64, blockHash, txHash common.Hash) string {
+	return filepath.Join(c.chainDir(chainID), "receipts", blockHash.Hex(), txHash.Hex()+".json")
+}
+
+// 加载链的区块号索引，调用方需持有 c.mu
+func (c *Cache) loadNumbers(chainID uint64) (map[uint64]common.Hash, error) {
+	if numbers, ok := c.numbers[chainID]; ok {
+		return numbers, nil
+	}
+	numbers := make(map[uint64]common.Hash)
+	err := readIndex(filepath.Join(c.chainDir(chainID), "numbers.jsonl"), func(line []byte, entry numberEntry) {
+		numbers[entry.Number] = entry.Hash
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	c.numbers[chainID] = numbers
+	return numbers, nil
+}
+
+func (c *Cache) BlockHash(chainID, number uint64) (common.Hash, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	numbers, err := c.loadNumbers(chainID)
+	if err != nil {
+		return common.Hash{}, false
+	}
+	hash, ok := numbers[number]
+	return hash, ok
+}
+
+func (c *Cache) GetBlock(chainID uint64, hash common.Hash) (*types.Block, error) {
+	path := c.blockPath(chainID, hash)
+	data, err := read(path)
+	if err != nil {
+		return nil, err
+	}
+	block := new(types.Block)
+	if err := rlp.DecodeBytes(data, block); err != nil {
+		return nil, fmt.Errorf("cached block %s: %w", hash.Hex(), err)
+	}
+	if block.Hash() != hash {
+		return nil, fmt.Errorf("cached block %s has mismatched hash %s", hash.Hex(), block.Hash().Hex())
+	}
+	return block, nil
+}
+
+func (c *Cache) PutBlock(chainID uint64, block *types.Block) error {
+	data, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		return err
+	}
+	if err := write(c.blockPath(chainID, block.Hash()), data); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	numbers, err := c.loadNumbers(chainID)
+	if err != nil {
+		return err
+	}
+	if numbers[block.NumberU64()] == block.Hash() {
+		return nil
+	}
+	line, err := json.Marshal(numberEntry{Number: block.NumberU64(), Hash: block.Hash()})
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(c.chainDir(chainID), "numbers.jsonl"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	numbers[block.NumberU64()] = block.Hash()
+	return nil
+}
+
+func (c *Cache) GetReceipt(chainID uint64, blockHash, txHash common.Hash) (*types.Receipt, error) {
+	data, err := read(c.receiptPath(chainID, blockHash, txHash))
+	if err != nil {
+		return nil, err
+	}
+	receipt := new(types.Receipt)
+	if err := json.Unmarshal(data, receipt); err != nil {
+		return nil, fmt.Errorf("cached receipt %s: %w", txHash.Hex(), err)
+	}
+	return receipt, nil
+}
+
+func (c *Cache) PutReceipt(chainID uint64, receipt *types.Receipt) error {
+	// 回执的 JSON 解码要求 logs 字段不为 null
+	if receipt.Logs == nil {
+		copied := *receipt
+		copied.Logs = []*types.Log{}
+		receipt = &copied
+	}
+	data, err := json.Marshal(receipt)
+	if err != nil {
+		return err
+	}
+	return write(c.receiptPath(chainID, receipt.BlockHash, receipt.TxHash), data)
+}
+
+func read(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotCached
+	}
+	if err != nil {
+		return nil, err
+	}
+	// 记录最近访问时间，供 Prune 淘汰最久未使用的条目
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return data, nil
+}
+
+// 先写临时文件再重命名，避免中断时留下不完整的条目
+func write(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// 缓存中的一个区块及其回执和区块号索引中的记录
+type entry struct {
+	chain      string
+	hash       string
+	paths      []string
+	receiptDir string // <chainID>/receipts/<blockHash>，没有回执时为空
+	size       int64
+	modTime    time.Time
+}
+
+// 按区块哈希汇总所有区块、回执文件和区块号索引，索引中每行的大小计入对应区块
+func (c *Cache) entries() (map[string]*entry, error) {
+	entries := make(map[string]*entry)
+	get := func(chain, hash string) *entry {
+		key := chain + "/" + hash
+		e, ok := entries[key]
+		if !ok {
+			e = &entry{chain: chain, hash: hash}
+			entries[key] = e
+		}
+		return e
+	}
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(c.dir, path)
+		if err != nil {
+			return err
+		}
+		// <chainID>/blocks/<hash>.rlp、<chainID>/receipts/<blockHash>/<tx>.json 或 <chainID>/numbers.jsonl
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		var e *entry
+		switch {
+		case len(parts) == 2 && parts[1] == "numbers.jsonl":
+			return readIndex(path, func(line []byte, entry numberEntry) {
+				get(parts[0], entry.Hash.Hex()).size += int64(len(line)) + 1
+			})
+		case len(parts) == 3 && parts[1] == "blocks":
+			e = get(parts[0], trimExt(parts[2]))
+		case len(parts) == 4 && parts[1] == "receipts":
+			e = get(parts[0], parts[2])
+			e.receiptDir = filepath.Dir(path)
+		default:
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		e.paths = append(e.paths, path)
+		e.size += info.Size()
+		if info.ModTime().After(e.modTime) {
+			e.modTime = info.ModTime()
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// 逐行读取区块号索引
+func readIndex(path string, fn func(line []byte, entry numberEntry)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry numberEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return fmt.Errorf("invalid cache index line: %w", err)
+		}
+		fn(scanner.Bytes(), entry)
+	}
+	return scanner.Err()
+}
+
+// 缓存总大小，包括区块号索引
+func (c *Cache) Size() (int64, error) {
+	entries, err := c.entries()
+	if err != nil {
+		return 0, err
+	}
+	var size int64
+	for _, e := range entries {
+		size += e.size
+	}
+	return size, nil
+}
+
+// 淘汰最久未使用的区块（连同其回执和索引记录），直到缓存大小不超过 maxBytes，返回删除的区块数和释放的字节数。
+// 索引中已没有对应文件的记录会被最先清理
+func (c *Cache) Prune(maxBytes int64) (int, int64, error) {
+	entries, err := c.entries()
+	if err != nil {
+		return 0, 0, err
+	}
+	var (
+		sorted = make([]*entry, 0, len(entries))
+		size   int64
+	)
+	for _, e := range entries {
+		sorted = append(sorted, e)
+		size += e.size
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].modTime.Before(sorted[j].modTime) })
+
+	var (
+		removed  int
+		freed    int64
+		unindex  = make(map[string]map[string]bool) // chainID -> 已删除的区块哈希
+		pruneErr error
+	)
+	for _, e := range sorted {
+		if size-freed <= maxBytes {
+			break
+		}
+		for _, path := range e.paths {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				pruneErr = err
+				break
+			}
+		}
+		if pruneErr != nil {
+			break
+		}
+		if e.receiptDir != "" {
+			os.Remove(e.receiptDir) // 回执目录为空时一并删除
+		}
+		if unindex[e.chain] == nil {
+			unindex[e.chain] = make(map[string]bool)
+		}
+		unindex[e.chain][e.hash] = true
+		if len(e.paths) > 0 {
+			removed++
+		}
+		freed += e.size
+	}
+
+	// 已删除的区块不能继续留在索引中，否则 BlockHash 会返回不存在的区块
+	for chain, hashes := range unindex {
+		if err := c.pruneIndex(chain, hashes); err != nil && pruneErr == nil {
+			pruneErr = err
+		}
+	}
+	return removed, freed, pruneErr
+}
+
+// 从链的区块号索引中删除 hashes 对应的记录，索引为空时删除索引文件
+func (c *Cache) pruneIndex(chain string, hashes map[string]bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if chainID, err := strconv.ParseUint(chain, 10, 64); err == nil {
+		delete(c.numbers, chainID) // 下次查询时重新加载
+	}
+	path := filepath.Join(c.dir, chain, "numbers.jsonl")
+	var kept bytes.Buffer
+	err := readIndex(path, func(line []byte, entry numberEntry) {
+		if !hashes[entry.Hash.Hex()] {
+			kept.Write(line)
+			kept.WriteByte('\n')
+		}
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if kept.Len() == 0 {
+		return os.Remove(path)
+	}
+	return write(path, kept.Bytes())
+}
+
+func trimExt(name string) string {
+	return name[:len(name)-len(filepath.Ext(name))]
+}
@@ -0,0 +1,151 @@
+package blockCache
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func testBlock(number uint64) *types.Block {
+	tx := types.NewTx(&types.LegacyTx{Nonce: number, GasPrice: big.NewInt(1), Gas: 21000, To: &common.Address{1}})
+	header := &types.Header{Number: new(big.Int).SetUint64(number), Time: number * 12, Difficulty: common.Big0}
+	return types.NewBlockWithHeader(header).WithBody(types.Body{Transactions: []*types.Transaction{tx}})
+}
+
+func testReceipt(block *types.Block) *types.Receipt {
+	tx := block.Transactions()[0]
+	return &types.Receipt{
+		Type:              tx.Type(),
+		Status:            types.ReceiptStatusSuccessful,
+		CumulativeGasUsed: 21000,
+		GasUsed:           21000,
+		Logs:              []*types.Log{},
+		TxHash:            tx.Hash(),
+		BlockHash:         block.Hash(),
+		BlockNumber:       block.Number(),
+		EffectiveGasPrice: big.NewInt(1),
+	}
+}
+
+func TestBlockAndReceipt(t *testing.T) {
+	cache, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := testBlock(7)
+	if _, ok := cache.BlockHash(1, 7); ok {
+		t.Fatal("unexpected block hash in empty cache")
+	}
+	if err := cache.PutBlock(1, block); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.PutReceipt(1, testReceipt(block)); err != nil {
+		t.Fatal(err)
+	}
+
+	// 重新打开后从索引恢复区块号
+	cache, _ = Open(cache.dir)
+	hash, ok := cache.BlockHash(1, 7)
+	if !ok || hash != block.Hash() {
+		t.Fatalf("BlockHash = %s %v, want %s", hash.Hex(), ok, block.Hash().Hex())
+	}
+	if _, ok := cache.BlockHash(5, 7); ok {
+		t.Fatal("block cached under a different chain ID")
+	}
+	got, err := cache.GetBlock(1, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Transactions()[0].Hash() != block.Transactions()[0].Hash() {
+		t.Fatal("cached block has different transactions")
+	}
+
+	txHash := block.Transactions()[0].Hash()
+	receipt, err := cache.GetReceipt(1, hash, txHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.GasUsed != 21000 || receipt.BlockNumber.Uint64() != 7 {
+		t.Fatalf("unexpected cached receipt: %+v", receipt)
+	}
+	if _, err := cache.GetReceipt(1, common.Hash{}, txHash); !errors.Is(err, ErrNotCached) {
+		t.Fatalf("got %v, want ErrNotCached", err)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	cache, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var blocks []*types.Block
+	for i := uint64(0); i < 3; i++ {
+		block := testBlock(i)
+		blocks = append(blocks, block)
+		cache.PutBlock(1, block)
+		cache.PutReceipt(1, testReceipt(block))
+
+		// 区块 0 最旧，区块 1 最新
+		mtime := time.Now().Add(-time.Hour * time.Duration(3-i))
+		if i == 1 {
+			mtime = time.Now()
+		}
+		os.Chtimes(cache.blockPath(1, block.Hash()), mtime, mtime)
+		os.Chtimes(cache.receiptPath(1, block.Hash(), block.Transactions()[0].Hash()), mtime, mtime)
+	}
+
+	size, err := cache.Size()
+	if err != nil {
+		t.Fatal(err)
+	}
+	removed, freed, err := cache.Prune(size - 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 || freed == 0 {
+		t.Fatalf("removed %d blocks, freed %d bytes; want 1 block", removed, freed)
+	}
+	if _, err := cache.GetBlock(1, blocks[0].Hash()); !errors.Is(err, ErrNotCached) {
+		t.Fatalf("oldest block not pruned: %v", err)
+	}
+	if newSize, _ := cache.Size(); newSize != size-freed {
+		t.Fatalf("cache size %d after freeing %d of %d bytes", newSize, freed, size)
+	}
+	// 索引中的记录随区块一起删除，重新打开后同样不可见
+	if _, ok := cache.BlockHash(1, 0); ok {
+		t.Fatal("pruned block still in the number index")
+	}
+	reopened, err := Open(cache.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := reopened.BlockHash(1, 0); ok {
+		t.Fatal("pruned block still in numbers.jsonl")
+	}
+	if hash, ok := reopened.BlockHash(1, 2); !ok || hash != blocks[2].Hash() {
+		t.Fatalf("BlockHash(2) = %s, %v; want %s", hash.Hex(), ok, blocks[2].Hash().Hex())
+	}
+
+	if removed, _, err = cache.Prune(0); err != nil || removed != 2 {
+		t.Fatalf("removed %d blocks, err %v; want 2", removed, err)
+	}
+	if size, _ := cache.Size(); size != 0 {
+		t.Fatalf("cache size %d after pruning everything", size)
+	}
+	if _, err := os.Stat(filepath.Join(cache.chainDir(1), "numbers.jsonl")); !os.IsNotExist(err) {
+		t.Errorf("empty numbers.jsonl not removed: %v", err)
+	}
+	// 只删除空的回执目录，不删除 blocks 等缓存自身的目录
+	if _, err := os.Stat(filepath.Join(cache.chainDir(1), "blocks")); err != nil {
+		t.Errorf("blocks directory removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(cache.chainDir(1), "receipts", blocks[2].Hash().Hex())); !os.IsNotExist(err) {
+		t.Errorf("empty receipt directory not removed: %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+// 基于 go-ethereum simulated 后端的端到端测试：部署测试合约，发送各类交易后
+// 分别运行扫描流程（ProcessBlock 到 PrintTxInfo）和 blob 发送流程
+package e2e
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// 测试合约：calldata 首字节为 0xff 时 revert，否则将 calldata 的前 32 字节写入 slot 0
+//
+//	PUSH1 0 CALLDATALOAD DUP1 PUSH1 0xf8 SHR PUSH1 0xff EQ PUSH1 0x11 JUMPI
+//	PUSH1 0 SSTORE STOP JUMPDEST PUSH1 0 DUP1 REVERT
+var (
+	testRuntime  = hexutil.MustDecode("0x6000358060f81c60ff14601157600055005b600080fd")
+	testInitCode = append(hexutil.MustDecode("0x601680600b6000396000f3"), testRuntime...)
+)
+
+// 交易池中同一账户不能同时存在普通交易和 blob 交易，blob 交易使用单独的账户
+var (
+	testKey, _     = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313e0ac59c7ba1c6f2a3")
+	testBlobKey, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+)
+
+type simChain struct {
+	sim      *simulated.Backend
+	client   simulated.Client
+	chainID  *big.Int
+	contract common.Address
+	nonces   map[common.Address]uint64
+}
+
+func newSimChain(t *testing.T) *simChain {
+	balance := new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+	sim := simulated.NewBackend(types.GenesisAlloc{
+		crypto.PubkeyToAddress(testKey.PublicKey):     {Balance: balance},
+		crypto.PubkeyToAddress(testBlobKey.PublicKey): {Balance: balance},
+	}, func(nodeConf *node.Config, ethConf *ethconfig.Config) {
+		// 开发链配置默认未启用 Cancun，blob 交易需要从创世块开始启用
+		config := *ethConf.Genesis.Config
+		config.CancunTime = new(uint64)
+		ethConf.Genesis.Config = &config
+		// 与主网常见配置一致，矿工只打包有效小费不低于 1 gwei 的交易
+		ethConf.Miner.GasPrice = big.NewInt(params.GWei)
+	})
+	t.Cleanup(func() { sim.Close() })
+
+	chain := &simChain{
+		sim:     sim,
+		client:  sim.Client(),
+		chainID: params.AllDevChainProtocolChanges.ChainID,
+		nonces:  make(map[common.Address]uint64),
+	}
+
+	deploy := chain.sendTx(t, testKey, &types.DynamicFeeTx{Gas: 200000, Data: testInitCode})
+	sim.Commit()
+	chain.contract = chain.receipt(t, deploy.Hash()).ContractAddress
+	return chain
+}
+
+// 补全 nonce、链 ID 和费用后签名发送
+func (c *simChain) sendTx(t *testing.T, key *ecdsa.PrivateKey, inner types.TxData) *types.Transaction {
+	t.Helper()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	switch tx := inner.(type) {
+	case *types.DynamicFeeTx:
+		tx.ChainID, tx.Nonce = c.chainID, c.nonces[from]
+		tx.GasTipCap, tx.GasFeeCap = big.NewInt(params.GWei), big.NewInt(10*params.GWei)
+	case *types.BlobTx:
+		tx.Nonce = c.nonces[from]
+	}
+	signed, err := types.SignNewTx(key, types.LatestSignerForChainID(c.chainID), inner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.client.SendTransaction(context.Background(), signed); err != nil {
+		t.Fatalf("failed to send tx: %v", err)
+	}
+	c.nonces[from]++
+	return signed
+}
+
+// 交易索引是异步建立的，出块后需要等待回执可查询
+func (c *simChain) receipt(t *testing.T, hash common.Hash) *types.Receipt {
+	t.Helper()
+	var err error
+	for i := 0; i < 100; i++ {
+		var receipt *types.Receipt
+		if receipt, err = c.client.TransactionReceipt(context.Background(), hash); err == nil {
+			return receipt
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("failed to get receipt for %s: %v", hash.Hex(), err)
+	return nil
+}
+
+// 后台定时出块，模拟发送流程等待打包时链在持续增长
+func (c *simChain) autoCommit(interval time.Duration) func() {
+	var (
+		stop = make(chan struct{})
+		wg   sync.WaitGroup
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				c.sim.Commit()
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		wg.Wait()
+	}
+}
+
+// 扫描器使用的 Backend，simulated 后端不提供 debug_traceTransaction
+type simBackend struct {
+	simulated.Client
+}
+
+func (simBackend) TraceTransaction(ctx context.Context, txHash common.Hash, config map[string]interface{}) (json.RawMessage, error) {
+	return nil, errors.New("tracing is not supported by the simulated backend")
+}
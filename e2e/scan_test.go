@@ -0,0 +1,145 @@
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
+
+	"test/blobTx/blobSender"
+	"test/blobTx/printTxInfo"
+)
+
+var (
+	storeData  = hexutil.MustDecode("0x01000000000000000000000000000000000000000000000000000000000000aa")
+	revertData = hexutil.MustDecode("0xff00000000000000000000000000000000000000000000000000000000000000")
+	blobData   = hexutil.MustDecode("0x02000000000000000000000000000000000000000000000000000000000000bb")
+)
+
+func TestScanPipeline(t *testing.T) {
+	chain := newSimChain(t)
+
+	sidecar, err := blobSender.MakeSidecar(blobSender.EncodeBlobs([]byte("scanner e2e")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract := chain.contract
+
+	// 区块内依次为：成功调用、revert、合约创建、blob 交易（覆盖已写入的 slot，gas 低于首次写入）
+	success := chain.sendTx(t, testKey, &types.DynamicFeeTx{Gas: 100000, To: &contract, Data: storeData})
+	reverted := chain.sendTx(t, testKey, &types.DynamicFeeTx{Gas: 100000, To: &contract, Data: revertData})
+	create := chain.sendTx(t, testKey, &types.DynamicFeeTx{Gas: 200000, Data: testInitCode})
+	blob := chain.sendTx(t, testBlobKey, &types.BlobTx{
+		ChainID:    uint256.MustFromBig(chain.chainID),
+		GasTipCap:  uint256.NewInt(1e9),
+		GasFeeCap:  uint256.NewInt(10e9),
+		Gas:        100000,
+		To:         contract,
+		Data:       blobData,
+		BlobFeeCap: uint256.NewInt(1e9),
+		BlobHashes: sidecar.BlobHashes(),
+		Sidecar:    sidecar,
+	})
+	chain.sim.Commit()
+	chain.receipt(t, blob.Hash())
+
+	// 合约创建交易没有 To，会被合约地址过滤掉，但必须已成功打包在索引 2
+	created := chain.receipt(t, create.Hash())
+	if created.Status != types.ReceiptStatusSuccessful || created.TransactionIndex != 2 || created.ContractAddress == (common.Address{}) {
+		t.Fatalf("contract creation receipt: status %d, index %d, contract %s", created.Status, created.TransactionIndex, created.ContractAddress.Hex())
+	}
+
+	ctx := context.Background()
+	head, err := chain.client.BlockNumber(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queryKeys := []string{"Hash", "Type", "4byte", "Status", "GasUsed", "BlobCount", "BlobGasUsed", "TransactionIndex"}
+	output := scan(t, simBackend{chain.client}, contract, head, 2, queryKeys)
+
+	want := renderExpected(queryKeys, []expectedTx{
+		{hash: success.Hash(), txType: types.DynamicFeeTxType, selector: "0x01000000", status: 1, gasUsed: 43289, index: 0},
+		{hash: reverted.Hash(), txType: types.DynamicFeeTxType, selector: "0xff000000", status: 0, gasUsed: 21181, index: 1},
+		{hash: blob.Hash(), txType: types.BlobTxType, selector: "0x02000000", status: 1, gasUsed: 26189, blobs: 1, blobGasUsed: 131072, index: 3},
+	})
+	if output != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", output, want)
+	}
+	if strings.Contains(output, create.Hash().Hex()) {
+		t.Fatalf("contract creation tx should be filtered out:\n%s", output)
+	}
+
+	// 只扫描失败的交易
+	failedKeys := []string{"Status", "Hash"}
+	output = scan(t, simBackend{chain.client}, contract, head, 0, failedKeys)
+	want = renderExpected(failedKeys, []expectedTx{{hash: reverted.Hash(), status: 0}})
+	if output != want {
+		t.Fatalf("unexpected failed-only output:\n%s\nwant:\n%s", output, want)
+	}
+}
+
+// 与 printTxInfo.go 相同的流程：逐块 ProcessBlock，排序后 PrintTxInfo
+func scan(t *testing.T, backend printTxInfo.Backend, contract common.Address, head uint64, statusFilter uint64, queryKeys []string) string {
+	results := make(chan printTxInfo.TxInfo, 100)
+	for number := uint64(0); number <= head; number++ {
+		printTxInfo.ProcessBlock(context.Background(), backend, new(big.Int).SetUint64(number), contract, "", statusFilter, nil, queryKeys, results)
+	}
+	close(results)
+
+	var infos []printTxInfo.TxInfo
+	for info := range results {
+		infos = append(infos, info)
+	}
+	printTxInfo.SortTxInfos(infos)
+
+	var buf bytes.Buffer
+	for _, info := range infos {
+		printTxInfo.FprintTxInfo(&buf, info.TxData, info.ReceiptData, queryKeys)
+	}
+	return buf.String()
+}
+
+type expectedTx struct {
+	hash        common.Hash
+	txType      uint8
+	selector    string
+	status      uint64
+	gasUsed     uint64
+	blobs       int
+	blobGasUsed uint64
+	index       uint
+}
+
+// 按 queryKeys 的顺序生成期望输出，交易字段和回执字段的前缀与 FprintTxInfo 一致
+func renderExpected(queryKeys []string, txs []expectedTx) string {
+	var sb strings.Builder
+	for _, tx := range txs {
+		fields := map[string]string{
+			"Hash":             "Transaction Hash: " + tx.hash.Hex(),
+			"Type":             fmt.Sprintf("Transaction Type: %d", tx.txType),
+			"4byte":            "Transaction 4byte: " + tx.selector,
+			"BlobCount":        fmt.Sprintf("Transaction BlobCount: %d", tx.blobs),
+			"Status":           fmt.Sprintf("Receipt Status: %d", tx.status),
+			"GasUsed":          fmt.Sprintf("Receipt GasUsed: %d", tx.gasUsed),
+			"BlobGasUsed":      fmt.Sprintf("Receipt BlobGasUsed: %d", tx.blobGasUsed),
+			"TransactionIndex": fmt.Sprintf("Receipt TransactionIndex: %d", tx.index),
+		}
+		sb.WriteString("\n===> Queried Fields:\n")
+		for _, key := range queryKeys {
+			line, ok := fields[key]
+			if !ok {
+				line = "Unknown query key: " + key
+			}
+			sb.WriteString(line + "\n")
+		}
+	}
+	return sb.String()
+}
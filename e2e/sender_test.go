@@ -0,0 +1,117 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/holiman/uint256"
+
+	"test/blobTx/blobSender"
+)
+
+var senderConfig = blobSender.ReplaceConfig{
+	WaitBlocks:   2,
+	PollInterval: 10 * time.Millisecond,
+}
+
+// 与 main.go 相同的流程：拆分 blob 后按 nonce 顺序构造交易，交给 SendBatch 发送并跟踪
+func buildBlobTxs(t *testing.T, chain *simChain, batches [][]kzg4844.Blob, tip, feeCap, blobFeeCap uint64) []*types.BlobTx {
+	from := crypto.PubkeyToAddress(testBlobKey.PublicKey)
+	nonces := blobSender.NewNonceManager(chain.nonces[from])
+
+	txs := make([]*types.BlobTx, len(batches))
+	for i, blobs := range batches {
+		sidecar, err := blobSender.MakeSidecar(blobs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txs[i] = &types.BlobTx{
+			ChainID:    uint256.MustFromBig(chain.chainID),
+			Nonce:      nonces.Next(),
+			GasTipCap:  uint256.NewInt(tip),
+			GasFeeCap:  uint256.NewInt(feeCap),
+			Gas:        100000,
+			To:         chain.contract,
+			BlobFeeCap: uint256.NewInt(blobFeeCap),
+			BlobHashes: sidecar.BlobHashes(),
+			Sidecar:    sidecar,
+		}
+	}
+	chain.nonces[from] += uint64(len(txs))
+	return txs
+}
+
+func signBlobTx(chain *simChain) blobSender.SignFn {
+	signer := types.LatestSignerForChainID(chain.chainID)
+	return func(tx *types.Transaction) (*types.Transaction, error) {
+		return types.SignTx(tx, signer, testBlobKey)
+	}
+}
+
+func TestSendBatch(t *testing.T) {
+	chain := newSimChain(t)
+
+	blobs := blobSender.EncodeBlobs(make([]byte, 3*blobSender.BytesPerBlob))
+	batches := blobSender.SplitBlobs(blobs, 2)
+	txs := buildBlobTxs(t, chain, batches, 1e9, 10e9, 1e9)
+
+	stop := chain.autoCommit(50 * time.Millisecond)
+	receipts, err := blobSender.SendBatch(context.Background(), chain.client, signBlobTx(chain), txs, senderConfig)
+	stop()
+	if err != nil {
+		t.Fatalf("SendBatch failed: %v", err)
+	}
+
+	head, err := chain.client.BlockNumber(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	queryKeys := []string{"Hash", "Nonce", "Status", "BlobCount", "BlobGasUsed"}
+	output := scan(t, simBackend{chain.client}, chain.contract, head, 2, queryKeys)
+
+	var want string
+	for i, receipt := range receipts {
+		if receipt == nil {
+			t.Fatalf("tx %d has no receipt", i)
+		}
+		want += fmt.Sprintf("\n===> Queried Fields:\nTransaction Hash: %s\nTransaction Nonce: %d\nReceipt Status: 1\nTransaction BlobCount: %d\nReceipt BlobGasUsed: %d\n",
+			receipt.TxHash.Hex(), txs[i].Nonce, len(batches[i]), len(batches[i])*131072)
+	}
+	if output != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", output, want)
+	}
+}
+
+func TestSendWithReplacement(t *testing.T) {
+	chain := newSimChain(t)
+
+	// GasFeeCap 等于 GasTipCap 时，扣除 base fee 后的有效小费低于矿工要求的 1 gwei，首个版本不会被打包
+	tx := buildBlobTxs(t, chain, [][]kzg4844.Blob{blobSender.EncodeBlobs([]byte("replace me"))}, 1e9, 1e9, 1)[0]
+	first, err := signBlobTx(chain)(types.NewTx(tx))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stop := chain.autoCommit(50 * time.Millisecond)
+	receipt, err := blobSender.SendWithReplacement(context.Background(), chain.client, signBlobTx(chain), tx, senderConfig)
+	stop()
+	if err != nil {
+		t.Fatalf("SendWithReplacement failed: %v", err)
+	}
+	if receipt.TxHash == first.Hash() {
+		t.Fatalf("underpriced tx %s was included without replacement", first.Hash().Hex())
+	}
+
+	included, _, err := chain.client.TransactionByHash(context.Background(), receipt.TxHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if included.Nonce() != tx.Nonce || included.GasTipCap().Uint64() != 2e9 || included.BlobGasFeeCap().Uint64() != 2 {
+		t.Fatalf("unexpected replacement: nonce %d, tipCap %v, blobFeeCap %v", included.Nonce(), included.GasTipCap(), included.BlobGasFeeCap())
+	}
+}
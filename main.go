@@ -4,9 +4,12 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"crypto/rand"
+	"flag"
 	"fmt"
 	"log"
 	"math/big"
+	"os"
+	"time"
 
 	"github.com/holiman/uint256"
 
@@ -14,14 +17,82 @@ import (
 	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+
+	"test/blobTx/blobData"
+	"test/blobTx/blobFee"
+	"test/blobTx/blobSender"
 )
 
+type txParams struct {
+	chainID    *big.Int
+	nonce      uint64
+	gasTipCap  *big.Int
+	gasFeeCap  *big.Int
+	gasLimit   uint64
+	blobFeeCap *big.Int
+}
+
 func main() {
+	// 子命令
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "broadcast":
+			broadcast(os.Args[2:])
+			return
+		case "verify":
+			verify(os.Args[2:])
+			return
+		case "feestats":
+			feeStats(os.Args[2:])
+			return
+		case "archive":
+			archiveLookup(os.Args[2:])
+			return
+		}
+	}
+
+	// 参数
+	rpcURL := flag.String("rpcURL", "https://ethereum-holesky.publicnode.com", "以太坊 RPC URL")
+	waitBlocks := flag.Uint64("waitBlocks", 3, "交易未被打包时等待的区块数，超过后提高费用用相同 nonce 重新发送")
+	maxGasFeeCap := flag.Uint64("maxGasFeeCap", 500, "重新发送时 GasFeeCap 的上限（gwei）")
+	maxBlobFeeCap := flag.Uint64("maxBlobFeeCap", 500, "重新发送时 BlobFeeCap 的上限（gwei）")
+	blobFeeLookAhead := flag.Uint64("blobFeeLookAhead", 3, "预估 BlobFeeCap 时向后预估的区块数（按 blob 满载计算）")
+	blobFeeMargin := flag.Uint64("blobFeeMargin", 20, "BlobFeeCap 的安全余量百分比")
+	blobFeeHistory := flag.Uint64("blobFeeHistory", 0, "大于 0 时按最近多少个区块的 eth_feeHistory 估算 BlobFeeCap")
+	blobFeePercentile := flag.Float64("blobFeePercentile", 90, "历史模式下选取的 blob 基础费用百分位")
+	blobCount := flag.Int("blobs", 1, "随机生成的 blob 数量，指定 -data 时忽略")
+	dataFile := flag.String("data", "", "要发布的数据文件，按 blob 容量拆分")
+	blobsPerTx := flag.Int("blobsPerTx", blobSender.MaxBlobsPerTx, "每笔交易携带的 blob 数量上限，超过时拆分为多笔连续 nonce 的交易")
+	archiveDir := flag.String("archive", "", "blob 存档目录，交易打包后保存发送的 blob")
+	dryRun := flag.Bool("dryRun", false, "只构造并签名交易，不发送，将结果写入 -out 指定的文件")
+	offline := flag.Bool("offline", false, "离线签名，不连接节点，交易参数全部由命令行提供（隐含 -dryRun）")
+	out := flag.String("out", "blobTx", "dryRun 输出文件前缀，生成 <out>.rlp 和 <out>.json")
+	chainIDFlag := flag.Uint64("chainID", 17000, "离线模式下的链 ID")
+	nonceFlag := flag.Uint64("nonce", 0, "离线模式下的 nonce")
+	gasLimitFlag := flag.Uint64("gasLimit", 21000, "离线模式下的 gas limit")
+	gasTipCapFlag := flag.String("gasTipCap", "1000000000", "离线模式下的 GasTipCap（wei）")
+	gasFeeCapFlag := flag.String("gasFeeCap", "20000000000", "离线模式下的 GasFeeCap（wei）")
+	blobFeeCapFlag := flag.String("blobFeeCap", "1000000000", "离线模式下的 BlobFeeCap（wei）")
+
+	flag.Parse()
+
+	feeCfg := blobFee.EstimateConfig{
+		LookAhead:     *blobFeeLookAhead,
+		MarginPct:     *blobFeeMargin,
+		HistoryBlocks: *blobFeeHistory,
+		Percentile:    *blobFeePercentile,
+	}
+	if err := feeCfg.Validate(); err != nil {
+		log.Fatalf("invalid -blobFeePercentile: %v", err)
+	}
+
 	//************** 构造非 blob 字段（与 EIP-1559 交易相同） **************
 	// Address: 0x111182649fA6e1C27C7456083ae356AD0d754036
 
@@ -36,32 +107,156 @@ func main() {
 	}
 	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
 
-	client, err := ethclient.Dial("https://ethereum-holesky.publicnode.com")
+	var (
+		client *ethclient.Client
+		txp    *txParams
+	)
+	if *offline {
+		*dryRun = true
+		txp = &txParams{
+			chainID:    new(big.Int).SetUint64(*chainIDFlag),
+			nonce:      *nonceFlag,
+			gasTipCap:  mustParseWei("gasTipCap", *gasTipCapFlag),
+			gasFeeCap:  mustParseWei("gasFeeCap", *gasFeeCapFlag),
+			gasLimit:   *gasLimitFlag,
+			blobFeeCap: mustParseWei("blobFeeCap", *blobFeeCapFlag),
+		}
+	} else {
+		client, err = ethclient.Dial(*rpcURL)
+		if err != nil {
+			log.Fatal("failed to connect to network", "err", err)
+		}
+		txp, err = fetchTxParams(context.Background(), client, fromAddress, feeCfg)
+		if err != nil {
+			log.Fatal("failed to prepare transaction", "err", err)
+		}
+	}
+
+	//************** 构造 blob 字段 **************
+
+	var blobs []kzg4844.Blob
+	if *dataFile != "" {
+		data, err := os.ReadFile(*dataFile)
+		if err != nil {
+			log.Fatal("failed to read data file", "err", err)
+		}
+		blobs = blobSender.EncodeBlobs(data)
+	} else {
+		for i := 0; i < *blobCount; i++ {
+			blobs = append(blobs, randBlob())
+		}
+	}
+	if len(blobs) == 0 {
+		log.Fatalf("no blobs to send")
+	}
+
+	// 超过单笔交易上限的 blob 拆分为多笔交易，nonce 在本地递增
+	nonces := blobSender.NewNonceManager(txp.nonce)
+	var blobTxs []*types.BlobTx
+	for _, batch := range blobSender.SplitBlobs(blobs, *blobsPerTx) {
+		sideCar, err := blobSender.MakeSidecar(batch)
+		if err != nil {
+			log.Fatal("failed to make sidecar", "err", err)
+		}
+		blobTxs = append(blobTxs, &types.BlobTx{
+			ChainID:    uint256.MustFromBig(txp.chainID),
+			Nonce:      nonces.Next(),
+			GasTipCap:  uint256.MustFromBig(txp.gasTipCap),
+			GasFeeCap:  uint256.MustFromBig(txp.gasFeeCap),
+			Gas:        txp.gasLimit,
+			To:         fromAddress,
+			BlobFeeCap: uint256.MustFromBig(txp.blobFeeCap),
+			BlobHashes: sideCar.BlobHashes(),
+			Sidecar:    sideCar,
+		})
+	}
+
+	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, txp.chainID)
 	if err != nil {
-		log.Fatal("failed to connect to network", "err", err)
+		log.Fatal("failed to create transactor", "chainID", txp.chainID, "err", err)
+	}
+	sign := func(tx *types.Transaction) (*types.Transaction, error) {
+		return auth.Signer(auth.From, tx)
 	}
 
-	chainID, err := client.NetworkID(context.Background())
+	// 只签名不发送，写入文件供审核后用 broadcast 子命令广播
+	if *dryRun {
+		for i, blobTx := range blobTxs {
+			signedTx, err := sign(types.NewTx(blobTx))
+			if err != nil {
+				log.Fatal("failed to sign the transaction", "err", err)
+			}
+			prefix := *out
+			if len(blobTxs) > 1 {
+				prefix = fmt.Sprintf("%s-%d", *out, i)
+			}
+			if err := blobSender.WriteSignedTx(signedTx, prefix+".rlp", prefix+".json"); err != nil {
+				log.Fatal("failed to write the signed transaction", "err", err)
+			}
+			fmt.Println("txHash: ", signedTx.Hash().Hex())
+			fmt.Println("written: ", prefix+".rlp", prefix+".json")
+		}
+		return
+	}
+
+	// 发送交易，未及时打包时按 blobpool 替换规则提高费用重新发送
+	replaceCfg := blobSender.ReplaceConfig{
+		WaitBlocks:    *waitBlocks,
+		MaxGasFeeCap:  new(uint256.Int).Mul(uint256.NewInt(*maxGasFeeCap), uint256.NewInt(params.GWei)),
+		MaxBlobFeeCap: new(uint256.Int).Mul(uint256.NewInt(*maxBlobFeeCap), uint256.NewInt(params.GWei)),
+		PollInterval:  4 * time.Second,
+	}
+	receipts, sendErr := blobSender.SendBatch(context.Background(), client, sign, blobTxs, replaceCfg)
+
+	// 部分发送失败时仍输出并存档已打包的交易，最后再以错误退出
+	var archive *blobData.Archive
+	if *archiveDir != "" {
+		if archive, err = blobData.OpenArchive(*archiveDir); err != nil {
+			log.Fatal("failed to open blob archive", "err", err)
+		}
+	}
+	for i, receipt := range receipts {
+		if receipt == nil {
+			continue
+		}
+		fmt.Println("txHash: ", receipt.TxHash.Hex())
+		fmt.Println("blockNumber: ", receipt.BlockNumber)
+
+		if archive != nil {
+			if err := archive.Store(receipt.TxHash, receipt.BlockNumber.Uint64(), blobTxs[i].BlobHashes, blobTxs[i].Sidecar.Blobs); err != nil {
+				log.Printf("failed to archive blobs for tx %s: %v", receipt.TxHash.Hex(), err)
+			}
+		}
+	}
+	if sendErr != nil {
+		log.Fatal("failed to send the transactions", "err", sendErr)
+	}
+
+}
+
+// 从节点获取构造交易所需的参数
+func fetchTxParams(ctx context.Context, client *ethclient.Client, fromAddress common.Address, feeCfg blobFee.EstimateConfig) (*txParams, error) {
+	chainID, err := client.NetworkID(ctx)
 	if err != nil {
-		log.Fatal("failed to get network ID", "err", err)
+		return nil, fmt.Errorf("failed to get network ID: %w", err)
 	}
 
-	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
+	nonce, err := client.PendingNonceAt(ctx, fromAddress)
 	if err != nil {
-		log.Fatal("failed to get pending nonce", "err", err)
+		return nil, fmt.Errorf("failed to get pending nonce: %w", err)
 	}
 
-	gasTipCap, err := client.SuggestGasTipCap(context.Background())
+	gasTipCap, err := client.SuggestGasTipCap(ctx)
 	if err != nil {
-		log.Fatal("failed to get suggest gas tip cap", "err", err)
+		return nil, fmt.Errorf("failed to get suggest gas tip cap: %w", err)
 	}
 
-	gasFeeCap, err := client.SuggestGasPrice(context.Background())
+	gasFeeCap, err := client.SuggestGasPrice(ctx)
 	if err != nil {
-		log.Fatal("failed to get suggest gas price", "err", err)
+		return nil, fmt.Errorf("failed to get suggest gas price: %w", err)
 	}
 
-	gasLimit, err := client.EstimateGas(context.Background(),
+	gasLimit, err := client.EstimateGas(ctx,
 		ethereum.CallMsg{
 			From:      fromAddress,
 			To:        &fromAddress,
@@ -72,73 +267,254 @@ func main() {
 			// 并且合约内使用 blobhash 操作码
 		})
 	if err != nil {
-		log.Fatal("failed to estimate gas", "err", err)
+		return nil, fmt.Errorf("failed to estimate gas: %w", err)
 	}
 
-	//************** 构造 blob 字段 **************
-
-	// 估算待打包区块的 blobFeeCap
-	parentHeader, err := client.HeaderByNumber(context.Background(), nil)
+	// 估算 blobFeeCap，预留未来若干区块内 blob 费用上涨的空间
+	blobFeeCap, err := blobFee.EstimateBlobFeeCap(ctx, blobFee.RPCClient{Client: client}, feeCfg)
 	if err != nil {
-		log.Fatal("failed to get previous block header", "err", err)
+		return nil, fmt.Errorf("failed to estimate blob fee cap: %w", err)
 	}
-	parentExcessBlobGas := eip4844.CalcExcessBlobGas(*parentHeader.ExcessBlobGas, *parentHeader.BlobGasUsed)
-	blobFeeCap := eip4844.CalcBlobFee(parentExcessBlobGas)
 
-	blob := randBlob()
-	sideCar := makeSidecar([]kzg4844.Blob{blob})
-	blobHashes := sideCar.BlobHashes()
+	return &txParams{
+		chainID:    chainID,
+		nonce:      nonce,
+		gasTipCap:  gasTipCap,
+		gasFeeCap:  gasFeeCap,
+		gasLimit:   gasLimit * 12 / 10,
+		blobFeeCap: blobFeeCap,
+	}, nil
+}
+
+func mustParseWei(name, value string) *big.Int {
+	wei, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		log.Fatalf("invalid %s: %s", name, value)
+	}
+	return wei
+}
 
-	tx := types.NewTx(&types.BlobTx{
-		ChainID:    uint256.MustFromBig(chainID),
-		Nonce:      nonce,
-		GasTipCap:  uint256.MustFromBig(gasTipCap),
-		GasFeeCap:  uint256.MustFromBig(gasFeeCap),
-		Gas:        gasLimit * 12 / 10,
-		To:         fromAddress,
-		BlobFeeCap: uint256.MustFromBig(blobFeeCap),
-		BlobHashes: blobHashes,
-		Sidecar:    sideCar,
-	})
+// 广播 dryRun 生成的已签名交易
+func broadcast(args []string) {
+	fs := flag.NewFlagSet("broadcast", flag.ExitOnError)
+	rpcURL := fs.String("rpcURL", "https://ethereum-holesky.publicnode.com", "以太坊 RPC URL")
+	file := fs.String("file", "blobTx.rlp", "dryRun 生成的已签名交易文件")
+	fs.Parse(args)
 
-	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
+	raw, tx, err := blobSender.ReadSignedTx(*file)
 	if err != nil {
-		log.Fatal("failed to create transactor", "chainID", chainID, "err", err)
+		log.Fatal("failed to read the signed transaction", "err", err)
 	}
 
-	signedTx, err := auth.Signer(auth.From, tx)
+	client, err := ethclient.Dial(*rpcURL)
 	if err != nil {
-		log.Fatal("failed to sign the transaction", "err", err)
+		log.Fatal("failed to connect to network", "err", err)
 	}
 
-	err = client.SendTransaction(context.Background(), signedTx)
+	hash, err := blobSender.BroadcastRawTx(context.Background(), client.Client(), raw)
 	if err != nil {
 		log.Fatal("failed to send the transaction", "err", err)
 	}
+	if hash != tx.Hash() {
+		log.Printf("Node returned hash %s, expected %s", hash.Hex(), tx.Hash().Hex())
+	}
+
+	fmt.Println("txHash: ", hash.Hex())
+}
+
+// 校验 blob 交易的 sidecar：commitment、KZG proof 以及 BlobHashes
+func verify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	rpcURL := fs.String("rpcURL", "https://ethereum-holesky.publicnode.com", "以太坊 RPC URL")
+	file := fs.String("file", "", "网络格式（交易 + sidecar）的 RLP 文件")
+	txHash := fs.String("tx", "", "交易哈希，通过 eth_getRawTransactionByHash 获取")
+	fs.Parse(args)
 
-	fmt.Println("txHash: ", signedTx.Hash().Hex())
+	var tx *types.Transaction
+	switch {
+	case *file != "":
+		var err error
+		if _, tx, err = blobSender.ReadSignedTx(*file); err != nil {
+			log.Fatal("failed to read the transaction", "err", err)
+		}
+	case *txHash != "":
+		client, err := ethclient.Dial(*rpcURL)
+		if err != nil {
+			log.Fatal("failed to connect to network", "err", err)
+		}
+		var raw hexutil.Bytes
+		if err := client.Client().CallContext(context.Background(), &raw, "eth_getRawTransactionByHash", common.HexToHash(*txHash)); err != nil {
+			log.Fatal("failed to get the transaction", "err", err)
+		}
+		tx = new(types.Transaction)
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			log.Fatal("failed to decode the transaction", "err", err)
+		}
+	default:
+		log.Fatalf("either -file or -tx is required")
+	}
 
+	if tx.Type() != types.BlobTxType {
+		log.Fatalf("tx %s is not a blob transaction (type %d)", tx.Hash().Hex(), tx.Type())
+	}
+	if tx.BlobTxSidecar() == nil {
+		log.Fatalf("tx %s has no sidecar, the node only returns the canonical encoding", tx.Hash().Hex())
+	}
+
+	checks, err := blobData.VerifySidecar(tx.BlobHashes(), tx.BlobTxSidecar())
+	if err != nil {
+		log.Fatal("failed to verify sidecar", "err", err)
+	}
+
+	fmt.Println("txHash: ", tx.Hash().Hex())
+	failed := 0
+	for _, check := range checks {
+		fmt.Printf("blob %d: commitment %s, proof %s, versionedHash %s %s\n",
+			check.Index, passFail(check.CommitmentOK), passFail(check.ProofOK), passFail(check.HashOK), check.VersionedHash.Hex())
+		if !check.OK() {
+			failed++
+			if check.Err != nil {
+				fmt.Printf("  error: %v\n", check.Err)
+			}
+			if !check.HashOK {
+				fmt.Printf("  expected versionedHash: %s\n", check.ExpectedHash.Hex())
+			}
+		}
+	}
+	fmt.Printf("%d/%d blobs passed\n", len(checks)-failed, len(checks))
+	if failed > 0 {
+		os.Exit(1)
+	}
 }
 
-func makeSidecar(blobs []kzg4844.Blob) *types.BlobTxSidecar {
-	var (
-		commitments []kzg4844.Commitment
-		proofs      []kzg4844.Proof
-	)
+// 未指定 -start 时统计的区块数，按 12 秒出块约为一天
+const defaultFeeStatsBlocks = 7200
+
+// 统计区块区间内的 blob 费用市场数据
+func feeStats(args []string) {
+	fs := flag.NewFlagSet("feestats", flag.ExitOnError)
+	rpcURL := fs.String("rpcURL", "https://ethereum-holesky.publicnode.com", "以太坊 RPC URL")
+	startBlock := fs.Int64("start", -1, "起始区块，默认为结束区块之前的 7200 个区块（约一天）；Cancun 之前的区块会被跳过")
+	endBlock := fs.Int64("end", -1, "结束区块，默认最新区块")
+	format := fs.String("format", "csv", "输出格式，csv 或 json")
+	out := fs.String("out", "", "输出文件，默认标准输出")
+	concurrency := fs.Int("concurrency", 10, "并行获取区块头的数量")
+	spikeFactor := fs.Float64("spikeFactor", 2, "blob 基础费用超过区间中位数多少倍视为尖峰")
+	fs.Parse(args)
+
+	client, err := ethclient.Dial(*rpcURL)
+	if err != nil {
+		log.Fatal("failed to connect to network", "err", err)
+	}
+	ctx := context.Background()
 
-	for _, blob := range blobs {
-		c, _ := kzg4844.BlobToCommitment(blob)
-		p, _ := kzg4844.ComputeBlobProof(blob, c)
+	end := uint64(*endBlock)
+	if *endBlock == -1 {
+		if end, err = client.BlockNumber(ctx); err != nil {
+			log.Fatal("failed to get the latest block", "err", err)
+		}
+	}
+
+	var start uint64
+	if *startBlock >= 0 {
+		start = uint64(*startBlock)
+	} else if end >= defaultFeeStatsBlocks {
+		start = end - defaultFeeStatsBlocks + 1
+	}
+
+	blocks, err := blobFee.CollectRange(ctx, client, start, end, *concurrency)
+	if err != nil {
+		log.Fatal("failed to collect blob fees", "err", err)
+	}
+	stats := blobFee.ComputeStats(blocks, *spikeFactor)
 
-		commitments = append(commitments, c)
-		proofs = append(proofs, p)
+	w := os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatal("failed to create output file", "err", err)
+		}
+		defer f.Close()
+		w = f
 	}
 
-	return &types.BlobTxSidecar{
-		Blobs:       blobs,
-		Commitments: commitments,
-		Proofs:      proofs,
+	switch *format {
+	case "csv":
+		err = blobFee.WriteCSV(w, blocks, stats)
+	case "json":
+		err = blobFee.WriteJSON(w, blocks, stats)
+	default:
+		log.Fatalf("unknown format: %s", *format)
+	}
+	if err != nil {
+		log.Fatal("failed to write output", "err", err)
+	}
+}
+
+// 从本地 blob 存档中查找 blob 并重新校验 commitment
+func archiveLookup(args []string) {
+	fs := flag.NewFlagSet("archive", flag.ExitOnError)
+	dir := fs.String("dir", "blobArchive", "blob 存档目录")
+	hash := fs.String("hash", "", "blob 的 versioned hash")
+	txHash := fs.String("tx", "", "交易哈希，列出并校验该交易的所有 blob")
+	block := fs.Int64("block", -1, "区块号，列出并校验该区块的所有 blob")
+	out := fs.String("out", "", "将 -hash 指定的 blob 写入文件")
+	fs.Parse(args)
+
+	archive, err := blobData.OpenArchive(*dir)
+	if err != nil {
+		log.Fatal("failed to open blob archive", "err", err)
+	}
+
+	var hashes []common.Hash
+	switch {
+	case *hash != "":
+		hashes = []common.Hash{common.HexToHash(*hash)}
+	case *txHash != "":
+		entry, err := archive.LookupTx(common.HexToHash(*txHash))
+		if err != nil {
+			log.Fatal("failed to look up tx", "err", err)
+		}
+		fmt.Printf("tx %s block %d\n", entry.TxHash.Hex(), entry.BlockNumber)
+		hashes = entry.VersionedHashes
+	case *block >= 0:
+		entries, err := archive.LookupBlock(uint64(*block))
+		if err != nil {
+			log.Fatal("failed to look up block", "err", err)
+		}
+		for _, entry := range entries {
+			fmt.Printf("tx %s block %d\n", entry.TxHash.Hex(), entry.BlockNumber)
+			hashes = append(hashes, entry.VersionedHashes...)
+		}
+	default:
+		log.Fatalf("one of -hash, -tx or -block is required")
+	}
+
+	failed := 0
+	for _, h := range hashes {
+		stored, err := archive.Get(h)
+		if err != nil {
+			fmt.Printf("blob %s: FAIL %v\n", h.Hex(), err)
+			failed++
+			continue
+		}
+		fmt.Printf("blob %s: commitment PASS 0x%x\n", h.Hex(), stored.Commitment[:])
+		if *out != "" && *hash != "" {
+			if err := os.WriteFile(*out, stored.Blob[:], 0644); err != nil {
+				log.Fatal("failed to write blob", "err", err)
+			}
+		}
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func passFail(ok bool) string {
+	if ok {
+		return "PASS"
 	}
+	return "FAIL"
 }
 
 func randBlob() kzg4844.Blob {
@@ -102,93 +102,208 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"flag"
 	"fmt"
 	"log"
 	"math/big"
+	"os"
 	"os/exec"
-	"sort"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 
+	"test/blobTx/blockCache"
 	"test/blobTx/printTxInfo"
+	"test/blobTx/sqliteSink"
 )
 
 func main() {
+	// 子命令
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "cache":
+			cacheCommand(os.Args[2:])
+			return
+		case "profile":
+			profileCommand(os.Args[2:])
+			return
+		case "trace":
+			traceCommand(os.Args[2:])
+			return
+		case "inspect":
+			inspectCommand(os.Args[2:])
+			return
+		}
+	}
+
 	// 参数
-	rpcURL := flag.String("rpcURL", "http://127.0.0.1:9545", "以太坊 RPC URL")
+	rpcURL := flag.String("rpcURL", "http://127.0.0.1:9545", "以太坊 RPC URL，多个节点用逗号分隔，可用 url#rps 为单个节点限速")
+	routing := flag.String("routing", printTxInfo.RouteRoundRobin, "多个节点间的路由策略，roundrobin 或 latency")
+	healthInterval := flag.Duration("healthInterval", 30*time.Second, "节点健康检查间隔")
 	contractAddress := flag.String("ca", "0xcf7ed3acca5a467e9e704c703e8d87f634fb0fc9", "合约地址")
-	startBlock := flag.Int64("start", 0, "起始区块")
-	endBlock := flag.Int64("end", -1, "结束区块，默认最新区块")
+	startBlock := flag.Int64("start", 0, "起始区块，负数表示最新区块之前的区块数，例如 -1000 表示最近 1000 个区块")
+	endBlock := flag.String("end", "latest", "结束区块，可以是区块号或 latest、safe、finalized")
+	since := flag.String("since", "", "起始时间，可以是 unix 时间戳、日期（如 2024-05-01，UTC）或距今时长（如 24h、7d），设置后覆盖 -start")
+	until := flag.String("until", "", "结束时间，格式同 -since，设置后覆盖 -end")
 	calldataPrefix := flag.String("calldata", "", "calldata的前10位")
+	aggregateField := flag.String("aggregate", "", "按指定字段（如 func、4byte、To）分组输出交易数、成功失败数、GasUsed 分布和总费用，不再逐笔打印")
 	queryKeys := flag.String("query", "BlockNumber,Hash,GasUsed,4byte,func", "查询的交易或执行信息，例如Hash,GasUsed等")
 	statusFilter := flag.Uint64("statusFilter", 2, "过滤特定Status值的交易，2表示不过滤，0表示失败交易，1表示成功交易")
-	concurrency := flag.Int("concurrency", 10, "并行处理的区块数量")
+	concurrency := flag.Int("concurrency", 10, "并行处理的区块数量，开启 -adaptive 时为并发上限")
+	rps := flag.Float64("rps", 0, "每秒最多发送的 RPC 请求数，0 表示不限制")
+	adaptiveConcurrency := flag.Bool("adaptive", false, "根据请求延迟和错误率自动调整并发数")
+	minConcurrency := flag.Int("minConcurrency", 1, "自适应模式下的最小并发数")
+	targetLatency := flag.Duration("targetLatency", time.Second, "自适应模式下的目标平均请求延迟，超过时降低并发")
 	signaturesFile := flag.String("signatures", "signaturesS.json", "签名文件路径")
+	txTypeFilter := flag.String("type", "", "只处理指定类型的交易，多个类型用逗号分隔，例如 2,3，默认不过滤")
+	beaconURL := flag.String("beaconURL", "", "信标节点 API URL，设置后可通过 BlobData 查询 blob 内容")
+	blobDir := flag.String("blobDir", "", "保存 blob 文件的目录，需要同时设置 -beaconURL")
+	blobDecoderName := flag.String("blobDecoder", "raw", "BlobData 使用的 blob 解码器，raw 或 opstack")
+	archiveDir := flag.String("archive", "", "blob 存档目录，保存扫描到的所有 blob，需要同时设置 -beaconURL")
+	cacheDir := flag.String("cacheDir", "", "区块和回执缓存目录，只缓存已确定的区块，默认不缓存")
+	cacheSize := flag.Int64("cacheSize", 1024, "缓存大小上限（MB），扫描结束后淘汰最久未使用的区块，0 表示不限制")
+	tokenInfo := flag.Bool("tokenInfo", false, "查询 transfers 字段中代币的 symbol 和 decimals 并格式化数量")
+	storageLayout := flag.String("storageLayout", "", "-ca 合约的存储布局文件（solc storageLayout 输出），用于解码 stateDiff 中的存储槽")
+	sqlitePath := flag.String("sqlite", "", "将扫描结果写入 SQLite 数据库，按交易哈希去重，可多次追加")
+	retries := flag.Int("retries", printTxInfo.DefaultRetryConfig.Attempts, "RPC 请求失败时的最多尝试次数，只重试超时、限流和 5xx 等临时错误")
+	retryDelay := flag.Duration("retryDelay", printTxInfo.DefaultRetryConfig.BaseDelay, "第一次重试前的等待时间，之后每次翻倍")
+	retryMaxDelay := flag.Duration("retryMaxDelay", printTxInfo.DefaultRetryConfig.MaxDelay, "重试等待时间上限")
 
 	flag.Parse()
 
+	// 解析交易类型过滤
+	var txTypes []uint8
+	if *txTypeFilter != "" {
+		for _, t := range strings.Split(*txTypeFilter, ",") {
+			txType, err := strconv.ParseUint(strings.TrimSpace(t), 10, 8)
+			if err != nil {
+				log.Fatalf("Invalid transaction type %q: %v", t, err)
+			}
+			txTypes = append(txTypes, uint8(txType))
+		}
+	}
+
 	// 加载签名文件
 	if err := printTxInfo.LoadSignatures(*signaturesFile); err != nil {
 		log.Fatalf("Failed to load signatures file: %v", err)
 	}
 
+	if *storageLayout != "" {
+		if err := printTxInfo.LoadStorageLayout(common.HexToAddress(*contractAddress), *storageLayout); err != nil {
+			log.Fatalf("Failed to load storage layout: %v", err)
+		}
+	}
+
 	// 检查是否安装了 cast 命令
 	if _, err := exec.LookPath("cast"); err != nil {
 		log.Fatalf("cast 命令未安装，请先安装 foundry: https://github.com/gakonst/foundry")
 	}
 
+	// 从信标节点获取 blob 内容
+	if *beaconURL != "" {
+		if err := printTxInfo.EnableBeaconBlobs(*beaconURL, *blobDir, *blobDecoderName); err != nil {
+			log.Fatalf("Failed to enable beacon blobs: %v", err)
+		}
+		if *archiveDir != "" {
+			if err := printTxInfo.EnableBlobArchive(*archiveDir); err != nil {
+				log.Fatalf("Failed to open blob archive: %v", err)
+			}
+		}
+	}
+
+	ctx := context.Background()
+
 	// 连接到以太坊客户端
-	client, err := ethclient.Dial(*rpcURL)
+	pool, err := printTxInfo.DialPool(ctx, *rpcURL, *routing)
 	if err != nil {
 		log.Fatalf("Failed to connect to the Ethereum client: %v", err)
 	}
+	pool.CheckHealth(ctx)
+	pool.StartHealthChecks(ctx, *healthInterval)
 
-	ctx := context.Background()
 	contractAddr := common.HexToAddress(*contractAddress)
 
-	// 获取最新区块
-	var latestBlock *big.Int
-	if *endBlock == -1 {
-		header, err := client.HeaderByNumber(ctx, nil)
-		if err != nil {
-			log.Fatalf("Failed to get the latest block: %v", err)
+	// 自适应模式下 -concurrency 为并发上限，从 -minConcurrency 开始根据延迟和错误率调整
+	var adaptive *printTxInfo.AdaptiveConcurrency
+	if *adaptiveConcurrency {
+		adaptive = printTxInfo.NewAdaptiveConcurrency(*minConcurrency, *concurrency, *targetLatency)
+	}
+	throttled := printTxInfo.NewThrottledBackend(pool, *rps, adaptive)
+	var backend printTxInfo.Backend = printTxInfo.NewRetryBackend(throttled, printTxInfo.RetryConfig{
+		Attempts:  *retries,
+		BaseDelay: *retryDelay,
+		MaxDelay:  *retryMaxDelay,
+	})
+
+	// 优先从本地缓存读取区块和回执
+	var cache *blockCache.Cache
+	if *cacheDir != "" {
+		if cache, err = blockCache.Open(*cacheDir); err != nil {
+			log.Fatalf("Failed to open cache: %v", err)
+		}
+		if backend, err = printTxInfo.NewCachedBackend(ctx, backend, cache); err != nil {
+			log.Fatalf("Failed to enable cache: %v", err)
 		}
-		latestBlock = header.Number
-	} else {
-		latestBlock = big.NewInt(*endBlock)
 	}
 
-	// 结果通道和等待组
-	results := make(chan printTxInfo.TxInfo, 100)
-	var wg sync.WaitGroup
-
-	// 启动并行处理的 Goroutines
-	for i := *startBlock; i <= latestBlock.Int64(); i += int64(*concurrency) {
-		start := i
-		end := i + int64(*concurrency) - 1
-		if end > latestBlock.Int64() {
-			end = latestBlock.Int64()
-		}
-		wg.Add(1)
-		go func(start, end int64) {
-			defer wg.Done()
-			for blockNumber := start; blockNumber <= end; blockNumber++ {
-				fmt.Printf("\r====> checking blockNum: %d\033[K", blockNumber)
-				printTxInfo.ProcessBlock(ctx, client, big.NewInt(blockNumber), contractAddr, *calldataPrefix, *statusFilter, strings.Split(*queryKeys, ","), results)
+	// 代币信息查询与扫描共用限速、重试和缓存包装
+	if *tokenInfo {
+		caller, ok := backend.(ethereum.ContractCaller)
+		if !ok {
+			log.Fatalf("Backend %T does not support eth_call, -tokenInfo is unavailable", backend)
+		}
+		printTxInfo.EnableTokenInfo(caller)
+	}
+
+	// 解析区块范围
+	start, end, err := printTxInfo.ResolveRange(ctx, backend, *startBlock, *endBlock)
+	if err != nil {
+		log.Fatalf("Failed to resolve block range: %v", err)
+	}
+	latestBlock := new(big.Int).SetUint64(end)
+	*startBlock = int64(start)
+
+	// 按时间选择区块范围，通过二分查找区块头确定区块号
+	if *since != "" || *until != "" {
+		resolver := printTxInfo.NewBlockTimeResolver(backend)
+		now, head := time.Now(), latestBlock.Uint64()
+		if *since != "" {
+			t, err := printTxInfo.ParseTime(*since, now)
+			if err != nil {
+				log.Fatalf("Invalid -since: %v", err)
+			}
+			start, err := resolver.BlockSince(ctx, t, head)
+			if err != nil {
+				log.Fatalf("Failed to resolve -since: %v", err)
+			}
+			*startBlock = int64(start)
+		}
+		if *until != "" {
+			t, err := printTxInfo.ParseTime(*until, now)
+			if err != nil {
+				log.Fatalf("Invalid -until: %v", err)
 			}
-		}(start, end)
-		time.Sleep(500 * time.Microsecond)
+			end, err := resolver.BlockUntil(ctx, t, head)
+			if err != nil {
+				log.Fatalf("Failed to resolve -until: %v", err)
+			}
+			latestBlock = new(big.Int).SetUint64(end)
+		}
+		log.Printf("Scanning blocks %d-%d", *startBlock, latestBlock)
 	}
 
-	// 启动一个 Goroutine 来关闭结果通道
+	// 固定数量的 worker 处理区块，结果通过通道汇总
+	results := make(chan printTxInfo.TxInfo, 100)
 	go func() {
-		wg.Wait()
+		printTxInfo.RunBlocks(ctx, uint64(*startBlock), latestBlock.Uint64(), *concurrency, adaptive, func(blockNumber uint64) {
+			fmt.Printf("\r====> checking blockNum: %d\033[K", blockNumber)
+			printTxInfo.ProcessBlock(ctx, backend, new(big.Int).SetUint64(blockNumber), contractAddr, *calldataPrefix, *statusFilter, txTypes, strings.Split(*queryKeys, ","), results)
+		})
 		close(results)
 	}()
 
@@ -198,15 +313,196 @@ func main() {
 		collectedResults = append(collectedResults, result)
 	}
 
-	sort.Slice(collectedResults, func(i, j int) bool {
-		if collectedResults[i].BlockNumber == collectedResults[j].BlockNumber {
-			return collectedResults[i].TransactionIndex < collectedResults[j].TransactionIndex
-		}
-		return collectedResults[i].BlockNumber < collectedResults[j].BlockNumber
-	})
+	printTxInfo.SortTxInfos(collectedResults)
 
 	// 打印结果
-	for _, result := range collectedResults {
-		printTxInfo.PrintTxInfo(result.TxData, result.ReceiptData, strings.Split(*queryKeys, ","))
+	if *aggregateField != "" {
+		fmt.Println()
+		printTxInfo.PrintAggregate(os.Stdout, *aggregateField, printTxInfo.Aggregate(collectedResults, *aggregateField))
+	} else {
+		for _, result := range collectedResults {
+			printTxInfo.PrintTxInfo(result.TxData, result.ReceiptData, strings.Split(*queryKeys, ","))
+		}
+	}
+
+	if *sqlitePath != "" {
+		sink, err := sqliteSink.Open(*sqlitePath)
+		if err != nil {
+			log.Fatalf("Failed to open SQLite database: %v", err)
+		}
+		inserted, skipped, err := sink.Write(ctx, sqliteSink.Scan{Contract: contractAddr, StartBlock: uint64(*startBlock), EndBlock: latestBlock.Uint64()}, collectedResults)
+		sink.Close()
+		if err != nil {
+			log.Fatalf("Failed to write SQLite database: %v", err)
+		}
+		log.Printf("Wrote %d transactions to %s, skipped %d already indexed", inserted, *sqlitePath, skipped)
+	}
+
+	if cache != nil && *cacheSize > 0 {
+		if _, _, err := cache.Prune(*cacheSize << 20); err != nil {
+			log.Printf("Failed to prune cache: %v", err)
+		}
+	}
+
+	if stats := pool.Stats(); len(stats) > 1 {
+		printTxInfo.PrintPoolStats(os.Stderr, stats)
+	}
+
+	// 有区块或回执获取失败时结果不完整，以非零状态退出
+	if failures := printTxInfo.FetchFailures(); len(failures) > 0 {
+		printTxInfo.PrintFailureSummary(os.Stderr, failures)
+		os.Exit(1)
+	}
+}
+
+// 缓存管理：cache prune 按大小上限淘汰最久未使用的区块，-maxSize 与扫描的 -cacheSize 含义相同
+func cacheCommand(args []string) {
+	if len(args) == 0 || args[0] != "prune" {
+		log.Fatalf("usage: %s cache prune [-dir dir] [-maxSize MB] [-all]", os.Args[0])
+	}
+	fs := flag.NewFlagSet("cache prune", flag.ExitOnError)
+	dir := fs.String("dir", "blockCache", "区块和回执缓存目录")
+	maxSize := fs.Int64("maxSize", 1024, "缓存大小上限（MB），淘汰最久未使用的区块，0 表示不限制")
+	all := fs.Bool("all", false, "清空缓存，忽略 -maxSize")
+	fs.Parse(args[1:])
+
+	cache, err := blockCache.Open(*dir)
+	if err != nil {
+		log.Fatalf("Failed to open cache: %v", err)
+	}
+	var (
+		removed int
+		freed   int64
+	)
+	switch {
+	case *all:
+		removed, freed, err = cache.Prune(0)
+	case *maxSize > 0:
+		removed, freed, err = cache.Prune(*maxSize << 20)
+	}
+	if err != nil {
+		log.Fatalf("Failed to prune cache: %v", err)
+	}
+	size, err := cache.Size()
+	if err != nil {
+		log.Fatalf("Failed to get cache size: %v", err)
+	}
+	fmt.Printf("removed %d blocks, freed %.1f MB, cache size %.1f MB\n", removed, float64(freed)/(1<<20), float64(size)/(1<<20))
+}
+
+// 按调用帧分析交易的 gas 消耗：profile [-format tree|folded] txhash...
+func profileCommand(args []string) {
+	fs := flag.NewFlagSet("profile", flag.ExitOnError)
+	rpcURL := fs.String("rpcURL", "http://127.0.0.1:9545", "以太坊 RPC URL，需要支持 debug_traceTransaction")
+	signaturesFile := fs.String("signatures", "signaturesS.json", "签名文件路径，用于解析调用的函数名")
+	format := fs.String("format", "tree", "输出格式，tree 为调用树，folded 为火焰图工具使用的折叠栈")
+	fs.Parse(args)
+	if fs.NArg() == 0 || (*format != "tree" && *format != "folded") {
+		log.Fatalf("usage: %s profile [-rpcURL url] [-signatures file] [-format tree|folded] txhash...", os.Args[0])
+	}
+
+	if err := printTxInfo.LoadSignatures(*signaturesFile); err != nil {
+		log.Fatalf("Failed to load signatures file: %v", err)
+	}
+
+	ctx := context.Background()
+	pool, err := printTxInfo.DialPool(ctx, *rpcURL, printTxInfo.RouteRoundRobin)
+	if err != nil {
+		log.Fatalf("Failed to connect to the Ethereum client: %v", err)
+	}
+	backend := printTxInfo.NewRetryBackend(pool, printTxInfo.DefaultRetryConfig)
+
+	for _, hash := range fs.Args() {
+		root, err := printTxInfo.ProfileGas(ctx, backend, common.HexToHash(hash))
+		if err != nil {
+			log.Fatalf("Failed to profile tx %s: %v", hash, err)
+		}
+		if *format == "folded" {
+			root.WriteFolded(os.Stdout)
+			continue
+		}
+		fmt.Printf("tx %s\n", hash)
+		root.WriteTree(os.Stdout)
+	}
+}
+
+// 导出交易的操作码级 trace：trace [-out file] [-maxDepth n] [-disableMemory] txhash
+// HTTP 节点的响应边读取边写出，其他节点的完整响应会先读入内存
+func traceCommand(args []string) {
+	fs := flag.NewFlagSet("trace", flag.ExitOnError)
+	rpcURL := fs.String("rpcURL", "http://127.0.0.1:9545", "以太坊 RPC URL，需要支持 debug_traceTransaction")
+	out := fs.String("out", "", "输出文件路径，默认为 <txhash>.jsonl")
+	maxDepth := fs.Int("maxDepth", 0, "只输出调用深度不超过该值的操作码，0 表示不限制；在本地过滤，只减小输出文件，不减小节点响应")
+	disableMemory := fs.Bool("disableMemory", false, "不记录内存快照，trace 较大时可显著减小节点响应和输出")
+	disableStack := fs.Bool("disableStack", false, "不记录栈快照")
+	disableStorage := fs.Bool("disableStorage", false, "不记录存储快照")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatalf("usage: %s trace [-rpcURL url] [-out file] [-maxDepth n] [-disableMemory] [-disableStack] [-disableStorage] txhash", os.Args[0])
+	}
+	txHash := common.HexToHash(fs.Arg(0))
+	if *out == "" {
+		*out = txHash.Hex() + ".jsonl"
+	}
+
+	ctx := context.Background()
+	pool, err := printTxInfo.DialPool(ctx, *rpcURL, printTxInfo.RouteRoundRobin)
+	if err != nil {
+		log.Fatalf("Failed to connect to the Ethereum client: %v", err)
+	}
+	backend := printTxInfo.NewRetryBackend(pool, printTxInfo.DefaultRetryConfig)
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatalf("Failed to create trace file: %v", err)
+	}
+	w := bufio.NewWriter(f)
+	steps, err := printTxInfo.WriteStructTrace(ctx, backend, txHash, printTxInfo.TraceConfig{
+		DisableMemory:  *disableMemory,
+		DisableStack:   *disableStack,
+		DisableStorage: *disableStorage,
+		MaxDepth:       *maxDepth,
+	}, w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Fatalf("Failed to trace tx %s: %v", txHash.Hex(), err)
+	}
+	log.Printf("Wrote %d steps to %s", steps, *out)
+}
+
+// 查看单笔交易：inspect txhash
+func inspectCommand(args []string) {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	rpcURL := fs.String("rpcURL", "http://127.0.0.1:9545", "以太坊 RPC URL，失败原因需要节点支持 debug_traceTransaction")
+	signaturesFile := fs.String("signatures", "signaturesS.json", "签名文件路径，用于解码参数和日志")
+	tokenInfo := fs.Bool("tokenInfo", false, "查询代币的 symbol 和 decimals 并格式化转移数量")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatalf("usage: %s inspect [-rpcURL url] [-signatures file] [-tokenInfo] txhash", os.Args[0])
+	}
+
+	if err := printTxInfo.LoadSignatures(*signaturesFile); err != nil {
+		log.Fatalf("Failed to load signatures file: %v", err)
+	}
+
+	ctx := context.Background()
+	pool, err := printTxInfo.DialPool(ctx, *rpcURL, printTxInfo.RouteRoundRobin)
+	if err != nil {
+		log.Fatalf("Failed to connect to the Ethereum client: %v", err)
+	}
+	backend := printTxInfo.NewRetryBackend(pool, printTxInfo.DefaultRetryConfig)
+	if *tokenInfo {
+		printTxInfo.EnableTokenInfo(backend)
+	}
+
+	ins, err := printTxInfo.InspectTx(ctx, backend, common.HexToHash(fs.Arg(0)))
+	if err != nil {
+		log.Fatalf("Failed to inspect tx: %v", err)
 	}
+	printTxInfo.FprintInspection(os.Stdout, ins)
 }
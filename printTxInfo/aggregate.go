@@ -0,0 +1,121 @@
+package printTxInfo
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"test/blobTx/blobFee"
+)
+
+// 按某个字段分组后的统计结果
+type GroupStats struct {
+	Key       string
+	Count     int
+	Success   int
+	Failed    int
+	MinGas    uint64
+	AvgGas    uint64
+	P50Gas    uint64
+	P95Gas    uint64
+	MaxGas    uint64
+	TotalFees *big.Int // 执行费用与 blob 费用之和（wei）
+}
+
+// 按 field（交易或回执字段，如 func、4byte、To）对结果分组统计，按交易数从多到少排序
+func Aggregate(infos []TxInfo, field string) []GroupStats {
+	type group struct {
+		stats GroupStats
+		gas   []uint64
+	}
+	groups := make(map[string]*group)
+	for _, info := range infos {
+		key := groupKey(info, field)
+		g, ok := groups[key]
+		if !ok {
+			g = &group{stats: GroupStats{Key: key, TotalFees: new(big.Int)}}
+			groups[key] = g
+		}
+		g.stats.Count++
+		if info.Receipt == nil {
+			continue
+		}
+		if info.Receipt.Status == types.ReceiptStatusSuccessful {
+			g.stats.Success++
+		} else {
+			g.stats.Failed++
+		}
+		g.gas = append(g.gas, info.Receipt.GasUsed)
+		g.stats.TotalFees.Add(g.stats.TotalFees, txFee(info.Receipt))
+	}
+
+	result := make([]GroupStats, 0, len(groups))
+	for _, g := range groups {
+		if len(g.gas) > 0 {
+			sort.Slice(g.gas, func(i, j int) bool { return g.gas[i] < g.gas[j] })
+			var sum uint64
+			for _, gas := range g.gas {
+				sum += gas
+			}
+			g.stats.MinGas = g.gas[0]
+			g.stats.MaxGas = g.gas[len(g.gas)-1]
+			g.stats.AvgGas = sum / uint64(len(g.gas))
+			g.stats.P50Gas = g.gas[blobFee.PercentileIndex(len(g.gas), 50)]
+			g.stats.P95Gas = g.gas[blobFee.PercentileIndex(len(g.gas), 95)]
+		}
+		result = append(result, g.stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count == result[j].Count {
+			return result[i].Key < result[j].Key
+		}
+		return result[i].Count > result[j].Count
+	})
+	return result
+}
+
+func groupKey(info TxInfo, field string) string {
+	value, ok := info.TxData[field]
+	if !ok {
+		value, ok = info.ReceiptData[field]
+	}
+	key := ""
+	if ok {
+		key = fmt.Sprint(value)
+	}
+	// 签名未知的函数退回到 4byte 选择器
+	if key == "" && field == "func" {
+		key = fmt.Sprint(info.TxData["4byte"])
+	}
+	if key == "" {
+		key = "(none)"
+	}
+	return key
+}
+
+// 交易实际支付的费用：GasUsed * EffectiveGasPrice + BlobGasUsed * BlobGasPrice
+func txFee(receipt *types.Receipt) *big.Int {
+	fee := new(big.Int)
+	if receipt.EffectiveGasPrice != nil {
+		fee.Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+	}
+	if receipt.BlobGasPrice != nil {
+		fee.Add(fee, new(big.Int).Mul(new(big.Int).SetUint64(receipt.BlobGasUsed), receipt.BlobGasPrice))
+	}
+	return fee
+}
+
+// 与 blobFee.Percentile 相同的 nearest-rank 取法，values 需已排序
+func PrintAggregate(w io.Writer, field string, stats []GroupStats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "%s\tcount\tsuccess\tfailed\tminGas\tavgGas\tp50Gas\tp95Gas\tmaxGas\ttotalFees(wei)\t\n", field)
+	for _, s := range stats {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%s\t\n",
+			s.Key, s.Count, s.Success, s.Failed, s.MinGas, s.AvgGas, s.P50Gas, s.P95Gas, s.MaxGas, s.TotalFees)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,40 @@
+package printTxInfo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAggregate(t *testing.T) {
+	infos := runScan(t, newTestBackend(t), scanFilter{statusFilter: 2})
+	stats := Aggregate(infos, "4byte")
+
+	if len(stats) != 3 {
+		t.Fatalf("got %d groups, want 3: %+v", len(stats), stats)
+	}
+	transfer := stats[0]
+	if transfer.Key != "0xa9059cbb" || transfer.Count != 2 || transfer.Success != 2 || transfer.Failed != 0 {
+		t.Fatalf("unexpected transfer group: %+v", transfer)
+	}
+	if transfer.MinGas != 50000 || transfer.MaxGas != 51000 || transfer.AvgGas != 50500 || transfer.P50Gas != 50000 || transfer.P95Gas != 51000 {
+		t.Fatalf("unexpected transfer gas stats: %+v", transfer)
+	}
+	// 相同交易数按 key 排序
+	if stats[1].Key != "0x" || stats[2].Key != "0x095ea7b3" || stats[2].Failed != 1 {
+		t.Fatalf("unexpected groups: %+v", stats[1:])
+	}
+	// blob 交易的费用只有 blob 部分：131072 * 1
+	if stats[1].TotalFees.Uint64() != 131072 {
+		t.Fatalf("blob group fees = %v, want 131072", stats[1].TotalFees)
+	}
+
+	var buf bytes.Buffer
+	if err := PrintAggregate(&buf, "4byte", stats); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 || !strings.Contains(lines[0], "p95Gas") || !strings.HasPrefix(strings.TrimSpace(lines[1]), "0xa9059cbb") {
+		t.Fatalf("unexpected table:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,103 @@
+package printTxInfo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// 扫描需要用到的节点接口，便于在测试中替换为内存实现
+type Backend interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	TraceTransaction(ctx context.Context, txHash common.Hash, config map[string]interface{}) (json.RawMessage, error)
+}
+
+// 基于 ethclient 的 Backend 实现
+type RPCBackend struct {
+	*ethclient.Client
+	URL string // HTTP 节点地址，用于流式读取 debug_traceTransaction 的响应，为空时不支持流式读取
+}
+
+func NewRPCBackend(client *ethclient.Client) *RPCBackend {
+	return &RPCBackend{Client: client}
+}
+
+func (b *RPCBackend) TraceTransaction(ctx context.Context, txHash common.Hash, config map[string]interface{}) (json.RawMessage, error) {
+	var result json.RawMessage
+	if err := b.Client.Client().CallContext(ctx, &result, "debug_traceTransaction", txHash, config); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// 以流的方式返回 debug_traceTransaction 的 JSON-RPC 响应，调用方负责关闭
+type TraceStreamer interface {
+	StreamTraceTransaction(ctx context.Context, txHash common.Hash, config map[string]interface{}) (io.ReadCloser, error)
+}
+
+// 直接发送 HTTP 请求并返回响应体，不经过 rpc.Client，避免把整个 trace 读入内存
+func (b *RPCBackend) StreamTraceTransaction(ctx context.Context, txHash common.Hash, config map[string]interface{}) (io.ReadCloser, error) {
+	if !strings.HasPrefix(b.URL, "http://") && !strings.HasPrefix(b.URL, "https://") {
+		return nil, errNoTraceStreamer
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "debug_traceTransaction",
+		"params":  []interface{}{txHash, config},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		return nil, rpc.HTTPError{StatusCode: resp.StatusCode, Status: resp.Status, Body: msg}
+	}
+	return resp.Body, nil
+}
+
+var errNoTraceStreamer = errors.New("backend does not support streaming traces")
+
+// 包装类型通过该函数把流式 trace 请求转发给内层 Backend
+func streamTrace(ctx context.Context, backend Backend, txHash common.Hash, config map[string]interface{}) (io.ReadCloser, error) {
+	streamer, ok := backend.(TraceStreamer)
+	if !ok {
+		return nil, errNoTraceStreamer
+	}
+	return streamer.StreamTraceTransaction(ctx, txHash, config)
+}
+
+var errNoContractCaller = errors.New("backend does not support eth_call")
+
+// 包装类型通过该函数把 eth_call 转发给内层 Backend，使代币信息查询同样经过限速和重试
+func callContract(ctx context.Context, backend Backend, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	caller, ok := backend.(ethereum.ContractCaller)
+	if !ok {
+		return nil, errNoContractCaller
+	}
+	return caller.CallContract(ctx, msg, blockNumber)
+}
@@ -0,0 +1,69 @@
+package printTxInfo
+
+import (
+	"context"
+	"log"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"test/blobTx/blobData"
+)
+
+var (
+	beaconClient *blobData.BeaconClient
+	blobSaveDir  string
+	blobDecoder  blobData.BlobDecoder
+	blobArchive  *blobData.Archive
+)
+
+// 启用信标节点获取 blob 内容，按 decoderName 指定的解码器解码，saveDir 非空时同时保存 blob 文件
+func EnableBeaconBlobs(beaconURL, saveDir, decoderName string) error {
+	decoder, err := blobData.GetDecoder(decoderName)
+	if err != nil {
+		return err
+	}
+	beaconClient = blobData.NewBeaconClient(beaconURL)
+	blobSaveDir = saveDir
+	blobDecoder = decoder
+	return nil
+}
+
+// 将扫描到的 blob 保存到本地存档，需要同时启用信标节点
+func EnableBlobArchive(dir string) error {
+	archive, err := blobData.OpenArchive(dir)
+	if err != nil {
+		return err
+	}
+	blobArchive = archive
+	return nil
+}
+
+func extractBlobData(ctx context.Context, block *types.Block, tx *types.Transaction) (map[string]interface{}, error) {
+	blobs, err := beaconClient.BlobsForTx(ctx, block.Time(), tx.BlobHashes())
+	if err != nil {
+		return nil, err
+	}
+
+	if blobArchive != nil {
+		if err := blobArchive.Store(tx.Hash(), block.NumberU64(), tx.BlobHashes(), blobs); err != nil {
+			log.Printf("Failed to archive blobs for tx %s: %v", tx.Hash().Hex(), err)
+		}
+	}
+
+	decoded, err := blobDecoder.Decode(blobs)
+	if err != nil {
+		log.Printf("Failed to decode blobs for tx %s with %s decoder: %v", tx.Hash().Hex(), blobDecoder.Name(), err)
+	}
+	fields := map[string]interface{}{
+		"BlobData": decoded,
+	}
+
+	if blobSaveDir != "" {
+		paths, err := blobData.SaveBlobs(blobSaveDir, tx.BlobHashes(), blobs)
+		if err != nil {
+			log.Printf("Failed to save blobs for tx %s: %v", tx.Hash().Hex(), err)
+		}
+		fields["BlobFiles"] = paths
+	}
+	return fields, nil
+}
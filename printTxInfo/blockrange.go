@@ -0,0 +1,67 @@
+package printTxInfo
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// 解析区块参数：非负整数为区块号，latest、safe、finalized 为对应的 rpc 区块标签，-1 等同于 latest
+func ParseBlockSpec(s string) (*big.Int, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "latest", "-1":
+		return big.NewInt(int64(rpc.LatestBlockNumber)), nil
+	case "safe":
+		return big.NewInt(int64(rpc.SafeBlockNumber)), nil
+	case "finalized":
+		return big.NewInt(int64(rpc.FinalizedBlockNumber)), nil
+	}
+	number, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid block %q, expected a block number or latest, safe, finalized", s)
+	}
+	return new(big.Int).SetUint64(number), nil
+}
+
+// 将区块参数解析为区块号，区块标签通过 HeaderByNumber 查询
+func ResolveBlock(ctx context.Context, backend Backend, s string) (uint64, error) {
+	number, err := ParseBlockSpec(s)
+	if err != nil {
+		return 0, err
+	}
+	if number.Sign() >= 0 {
+		return number.Uint64(), nil
+	}
+	header, err := backend.HeaderByNumber(ctx, number)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the %s block: %w", rpc.BlockNumber(number.Int64()), err)
+	}
+	return header.Number.Uint64(), nil
+}
+
+// 解析扫描范围：end 为区块号或区块标签，start 为负数时表示最新区块之前的区块数，例如 -1000 表示最近 1000 个区块
+func ResolveRange(ctx context.Context, backend Backend, start int64, end string) (uint64, uint64, error) {
+	endNumber, err := ResolveBlock(ctx, backend, end)
+	if err != nil {
+		return 0, 0, err
+	}
+	startNumber := uint64(start)
+	if start < 0 {
+		head, err := ResolveBlock(ctx, backend, "latest")
+		if err != nil {
+			return 0, 0, err
+		}
+		startNumber = 0
+		if back := uint64(-start); back <= head {
+			startNumber = head + 1 - back
+		}
+	}
+	if startNumber > endNumber {
+		return 0, 0, fmt.Errorf("invalid block range %d-%d", startNumber, endNumber)
+	}
+	return startNumber, endNumber, nil
+}
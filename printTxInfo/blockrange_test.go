@@ -0,0 +1,48 @@
+package printTxInfo
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestResolveRange(t *testing.T) {
+	backend := newTestBackend(t) // 最新区块为 2
+	backend.AddBlock(types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0)}), nil)
+	backend.SetTag(rpc.SafeBlockNumber, 1)
+	backend.SetTag(rpc.FinalizedBlockNumber, 0)
+	tests := []struct {
+		start      int64
+		end        string
+		wantStart  uint64
+		wantEnd    uint64
+		shouldFail bool
+	}{
+		{start: 0, end: "latest", wantStart: 0, wantEnd: 2},
+		{start: 1, end: "1", wantStart: 1, wantEnd: 1},
+		{start: 0, end: "-1", wantStart: 0, wantEnd: 2},
+		{start: 0, end: "safe", wantStart: 0, wantEnd: 1},
+		{start: 0, end: "finalized", wantStart: 0, wantEnd: 0},
+		{start: -2, end: "latest", wantStart: 1, wantEnd: 2},
+		{start: -2, end: "safe", wantStart: 1, wantEnd: 1}, // 负数 start 相对最新区块而不是 end
+		{start: -1, end: "finalized", shouldFail: true},    // 最新区块 2 在 finalized 区块 0 之后
+		{start: -1000, end: "latest", wantStart: 0, wantEnd: 2},
+		{start: 2, end: "1", shouldFail: true},
+		{start: 0, end: "pending", shouldFail: true},
+	}
+	for _, tt := range tests {
+		start, end, err := ResolveRange(context.Background(), backend, tt.start, tt.end)
+		if tt.shouldFail {
+			if err == nil {
+				t.Errorf("ResolveRange(%d, %q) = %d, %d, want error", tt.start, tt.end, start, end)
+			}
+			continue
+		}
+		if err != nil || start != tt.wantStart || end != tt.wantEnd {
+			t.Errorf("ResolveRange(%d, %q) = %d, %d, %v; want %d, %d", tt.start, tt.end, start, end, err, tt.wantStart, tt.wantEnd)
+		}
+	}
+}
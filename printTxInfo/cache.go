@@ -0,0 +1,145 @@
+package printTxInfo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"test/blobTx/blockCache"
+)
+
+// 节点不支持 finalized 标签时，视为已确定的区块深度
+const fallbackFinalityDepth = 64
+
+// 优先从本地缓存读取已确定区块和回执的 Backend，未确定的区块直接转发给节点
+type CachedBackend struct {
+	Backend
+	cache     *blockCache.Cache
+	chainID   uint64
+	finalized uint64
+
+	// 已确定区块中交易所在的区块哈希，用于按区块哈希查找回执
+	txBlocks *lru.Cache[common.Hash, common.Hash]
+}
+
+func NewCachedBackend(ctx context.Context, backend Backend, cache *blockCache.Cache) (*CachedBackend, error) {
+	chainID, err := backend.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+	header, err := backend.HeaderByNumber(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber)))
+	var finalized uint64
+	if err == nil {
+		finalized = header.Number.Uint64()
+	} else {
+		log.Printf("Failed to get the finalized block, caching blocks %d deep: %v", fallbackFinalityDepth, err)
+		if header, err = backend.HeaderByNumber(ctx, nil); err != nil {
+			return nil, fmt.Errorf("failed to get the latest block: %w", err)
+		}
+		if header.Number.Uint64() > fallbackFinalityDepth {
+			finalized = header.Number.Uint64() - fallbackFinalityDepth
+		}
+	}
+	return &CachedBackend{
+		Backend:   backend,
+		cache:     cache,
+		chainID:   chainID.Uint64(),
+		finalized: finalized,
+		txBlocks:  lru.NewCache[common.Hash, common.Hash](100000),
+	}, nil
+}
+
+func (b *CachedBackend) isFinalized(number *big.Int) bool {
+	return number != nil && number.Sign() >= 0 && number.Uint64() <= b.finalized
+}
+
+func (b *CachedBackend) remember(block *types.Block) {
+	for _, tx := range block.Transactions() {
+		b.txBlocks.Add(tx.Hash(), block.Hash())
+	}
+}
+
+func (b *CachedBackend) cachedBlock(number *big.Int) *types.Block {
+	if !b.isFinalized(number) {
+		return nil
+	}
+	hash, ok := b.cache.BlockHash(b.chainID, number.Uint64())
+	if !ok {
+		return nil
+	}
+	block, err := b.cache.GetBlock(b.chainID, hash)
+	if err != nil {
+		if !errors.Is(err, blockCache.ErrNotCached) {
+			log.Printf("Failed to read block %d from cache: %v", number, err)
+		}
+		return nil
+	}
+	return block
+}
+
+func (b *CachedBackend) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	if block := b.cachedBlock(number); block != nil {
+		b.remember(block)
+		return block, nil
+	}
+	block, err := b.Backend.BlockByNumber(ctx, number)
+	if err != nil || !b.isFinalized(block.Number()) {
+		return block, err
+	}
+	if err := b.cache.PutBlock(b.chainID, block); err != nil {
+		log.Printf("Failed to cache block %d: %v", block.NumberU64(), err)
+	}
+	b.remember(block)
+	return block, nil
+}
+
+func (b *CachedBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if block := b.cachedBlock(number); block != nil {
+		return block.Header(), nil
+	}
+	return b.Backend.HeaderByNumber(ctx, number)
+}
+
+// 只有通过 BlockByNumber 获取过的已确定区块中的交易才会使用缓存
+func (b *CachedBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	blockHash, ok := b.txBlocks.Get(txHash)
+	if !ok {
+		return b.Backend.TransactionReceipt(ctx, txHash)
+	}
+	receipt, err := b.cache.GetReceipt(b.chainID, blockHash, txHash)
+	if err == nil {
+		return receipt, nil
+	}
+	if !errors.Is(err, blockCache.ErrNotCached) {
+		log.Printf("Failed to read receipt %s from cache: %v", txHash.Hex(), err)
+	}
+
+	receipt, err = b.Backend.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if receipt.BlockHash == blockHash {
+		if err := b.cache.PutReceipt(b.chainID, receipt); err != nil {
+			log.Printf("Failed to cache receipt %s: %v", txHash.Hex(), err)
+		}
+	}
+	return receipt, nil
+}
+
+// eth_call 结果不缓存，直接转发
+func (b *CachedBackend) StreamTraceTransaction(ctx context.Context, txHash common.Hash, config map[string]interface{}) (io.ReadCloser, error) {
+	return streamTrace(ctx, b.Backend, txHash, config)
+}
+
+func (b *CachedBackend) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return callContract(ctx, b.Backend, msg, blockNumber)
+}
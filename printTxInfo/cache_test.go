@@ -0,0 +1,67 @@
+package printTxInfo
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"test/blobTx/blockCache"
+)
+
+// 统计区块和回执请求次数的 Backend
+type countingBackend struct {
+	Backend
+	blocks, receipts int32
+}
+
+func (b *countingBackend) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	atomic.AddInt32(&b.blocks, 1)
+	return b.Backend.BlockByNumber(ctx, number)
+}
+
+func (b *countingBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	atomic.AddInt32(&b.receipts, 1)
+	return b.Backend.TransactionReceipt(ctx, txHash)
+}
+
+func TestCachedBackend(t *testing.T) {
+	ResetFetchFailures()
+	dir := t.TempDir()
+	counting := &countingBackend{Backend: newTestBackend(t)}
+
+	scan := func() []TxInfo {
+		cache, err := blockCache.Open(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		backend, err := NewCachedBackend(context.Background(), counting, cache)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return runScan(t, backend, scanFilter{statusFilter: 2})
+	}
+
+	first := scan()
+	if counting.blocks != 2 || counting.receipts != 4 {
+		t.Fatalf("first scan made %d block and %d receipt requests, want 2 and 4", counting.blocks, counting.receipts)
+	}
+
+	// 第二次扫描全部命中缓存，结果与第一次相同
+	second := scan()
+	if counting.blocks != 2 || counting.receipts != 4 {
+		t.Fatalf("second scan made %d block and %d receipt requests, want none", counting.blocks-2, counting.receipts-4)
+	}
+	if len(second) != len(first) {
+		t.Fatalf("got %d results from cache, want %d", len(second), len(first))
+	}
+	for i := range first {
+		if fmt.Sprint(first[i].TxData) != fmt.Sprint(second[i].TxData) || fmt.Sprint(first[i].ReceiptData) != fmt.Sprint(second[i].ReceiptData) {
+			t.Fatalf("cached result %d differs:\n%v %v\n%v %v", i, first[i].TxData, first[i].ReceiptData, second[i].TxData, second[i].ReceiptData)
+		}
+	}
+}
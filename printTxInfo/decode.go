@@ -0,0 +1,190 @@
+package printTxInfo
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// 按签名文件中的 ABI 解码出的参数
+type DecodedArg struct {
+	Name    string
+	Type    string
+	Indexed bool
+	Value   string
+}
+
+// 按签名文件中的事件 ABI 解码出的日志
+type DecodedLog struct {
+	Index   uint
+	Address common.Address
+	Event   string // 事件签名，无法解码时为空
+	Args    []DecodedArg
+	Log     *types.Log
+	Err     error // 解码失败的原因，由调用方按需记录
+}
+
+var (
+	abiMu    sync.Mutex
+	abiCache = make(map[string]*abi.ABI)
+)
+
+// 由签名文件中的一条记录构造只包含该函数或事件的 ABI，结果会被缓存
+func signatureABI(sig Signature) (*abi.ABI, error) {
+	abiMu.Lock()
+	defer abiMu.Unlock()
+
+	if parsed, ok := abiCache[sig.Signature]; ok {
+		return parsed, nil
+	}
+	inputs := sig.Inputs
+	if len(inputs) == 0 {
+		inputs = json.RawMessage("[]")
+	}
+	name := sig.Name
+	if i := strings.Index(name, "("); i >= 0 {
+		name = name[:i]
+	}
+	entry, err := json.Marshal([]map[string]interface{}{{"type": sig.Type, "name": name, "inputs": inputs}})
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := abi.JSON(strings.NewReader(string(entry)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ABI for %s: %w", sig.Name, err)
+	}
+	abiCache[sig.Signature] = &parsed
+	return &parsed, nil
+}
+
+// 按 4byte 选择器解码 calldata，返回函数签名和参数；签名文件中没有对应函数时 ok 为 false
+func DecodeCalldata(data []byte) (string, []DecodedArg, bool, error) {
+	if len(data) < 4 {
+		return "", nil, false, nil
+	}
+	sig, ok := signaturesMap["0x"+hex.EncodeToString(data[:4])]
+	if !ok || sig.Type != "function" {
+		return "", nil, false, nil
+	}
+	parsed, err := signatureABI(sig)
+	if err != nil {
+		return sig.Name, nil, true, err
+	}
+	for _, method := range parsed.Methods {
+		values, err := method.Inputs.Unpack(data[4:])
+		if err != nil {
+			return sig.Name, nil, true, fmt.Errorf("failed to decode %s calldata: %w", sig.Name, err)
+		}
+		args := make([]DecodedArg, len(method.Inputs))
+		for i, input := range method.Inputs {
+			args[i] = DecodedArg{Name: input.Name, Type: input.Type.String(), Value: FormatArg(values[i])}
+		}
+		return sig.Name, args, true, nil
+	}
+	return sig.Name, nil, true, nil
+}
+
+// 按 topic0 解码日志，签名文件中没有对应事件时只返回原始日志
+func DecodeLog(log *types.Log) (*DecodedLog, error) {
+	decoded := &DecodedLog{Index: log.Index, Address: log.Address, Log: log}
+	if len(log.Topics) == 0 {
+		return decoded, nil
+	}
+	sig, ok := signaturesMap[log.Topics[0].Hex()]
+	if !ok || sig.Type != "event" {
+		return decoded, nil
+	}
+	decoded.Event = sig.Name
+
+	parsed, err := signatureABI(sig)
+	if err != nil {
+		return decoded, err
+	}
+	for _, event := range parsed.Events {
+		// 按位置解码，参数同名或未命名时也不会互相覆盖
+		var indexed abi.Arguments
+		for i, input := range event.Inputs {
+			if input.Indexed {
+				input.Name = fmt.Sprintf("topic%d", i)
+				indexed = append(indexed, input)
+			}
+		}
+		if len(log.Topics)-1 != len(indexed) {
+			return decoded, fmt.Errorf("event %s expects %d indexed topics, got %d", sig.Name, len(indexed), len(log.Topics)-1)
+		}
+		topics := make(map[string]interface{})
+		if err := abi.ParseTopicsIntoMap(topics, indexed, log.Topics[1:]); err != nil {
+			return decoded, fmt.Errorf("failed to decode %s topics: %w", sig.Name, err)
+		}
+		data, err := event.Inputs.NonIndexed().UnpackValues(log.Data)
+		if err != nil {
+			return decoded, fmt.Errorf("failed to decode %s data: %w", sig.Name, err)
+		}
+		next := 0
+		for i, input := range event.Inputs {
+			var value interface{}
+			if input.Indexed {
+				value = topics[fmt.Sprintf("topic%d", i)]
+			} else {
+				value = data[next]
+				next++
+			}
+			name := input.Name
+			if name == "" {
+				name = fmt.Sprintf("arg%d", i)
+			}
+			decoded.Args = append(decoded.Args, DecodedArg{Name: name, Type: input.Type.String(), Indexed: input.Indexed, Value: FormatArg(value)})
+		}
+	}
+	return decoded, nil
+}
+
+// 将解码后的参数格式化为字符串：地址和哈希使用十六进制，字节数组使用 0x 前缀，数组逐项格式化
+func FormatArg(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case common.Address:
+		return v.Hex()
+	case common.Hash:
+		return v.Hex()
+	case []byte:
+		return "0x" + hex.EncodeToString(v)
+	case *big.Int:
+		return v.String()
+	case string:
+		return v
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return "0x" + hex.EncodeToString(b)
+		}
+		fallthrough
+	case reflect.Slice:
+		items := make([]string, rv.Len())
+		for i := range items {
+			items[i] = FormatArg(rv.Index(i).Interface())
+		}
+		return "[" + strings.Join(items, ",") + "]"
+	case reflect.Struct:
+		fields := make([]string, rv.NumField())
+		for i := range fields {
+			fields[i] = FormatArg(rv.Field(i).Interface())
+		}
+		return "(" + strings.Join(fields, ",") + ")"
+	}
+	return fmt.Sprint(value)
+}
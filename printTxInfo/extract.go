@@ -3,6 +3,7 @@ package printTxInfo
 import (
 	"encoding/hex"
 	"fmt"
+	"math/big"
 	"os/exec"
 	"strings"
 
@@ -29,6 +30,12 @@ func extractTxData(tx *types.Transaction) map[string]interface{} {
 		}
 	}
 
+	// 创建合约的交易没有 To
+	to := ""
+	if tx.To() != nil {
+		to = tx.To().Hex()
+	}
+
 	// 映射交易字段名
 	txFields := map[string]interface{}{
 		"Hash":     tx.Hash().Hex(),
@@ -36,10 +43,16 @@ func extractTxData(tx *types.Transaction) map[string]interface{} {
 		"GasPrice": tx.GasPrice(),
 		"Gas":      tx.Gas(),
 		"Value":    tx.Value(),
-		"To":       tx.To().Hex(),
+		"To":       to,
 		"Data":     calldata,
 		"4byte":    functionSignature,
 		"func":     funcName,
+
+		// blob 交易（type 3）字段，其他类型交易为空值
+		"Type":       tx.Type(),
+		"BlobHashes": tx.BlobHashes(),
+		"BlobCount":  len(tx.BlobHashes()),
+		"BlobFeeCap": bigOrEmpty(tx.BlobGasFeeCap()),
 	}
 
 	return txFields
@@ -59,7 +72,17 @@ func extractReceiptData(receipt *types.Receipt) map[string]interface{} {
 		"BlockHash":         receipt.BlockHash.Hex(),
 		"BlockNumber":       receipt.BlockNumber.String(),
 		"TransactionIndex":  receipt.TransactionIndex,
+		"BlobGasUsed":       receipt.BlobGasUsed,
+		"BlobGasPrice":      bigOrEmpty(receipt.BlobGasPrice),
 	}
 
 	return reFields
 }
+
+// 非 blob 交易没有 blob 费用字段，输出空字符串而不是 <nil>
+func bigOrEmpty(v *big.Int) interface{} {
+	if v == nil {
+		return ""
+	}
+	return v
+}
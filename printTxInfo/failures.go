@@ -0,0 +1,69 @@
+package printTxInfo
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// 重试后仍未能获取的区块或回执
+type FetchFailure struct {
+	BlockNumber uint64
+	TxHash      common.Hash // 为空表示整个区块获取失败
+	Err         error
+}
+
+var (
+	failuresMu    sync.Mutex
+	fetchFailures []FetchFailure
+)
+
+func recordFailure(failure FetchFailure) {
+	failuresMu.Lock()
+	defer failuresMu.Unlock()
+	fetchFailures = append(fetchFailures, failure)
+}
+
+// 返回扫描过程中记录的所有失败，按区块号排序
+func FetchFailures() []FetchFailure {
+	failuresMu.Lock()
+	defer failuresMu.Unlock()
+
+	failures := append([]FetchFailure{}, fetchFailures...)
+	sort.SliceStable(failures, func(i, j int) bool {
+		return failures[i].BlockNumber < failures[j].BlockNumber
+	})
+	return failures
+}
+
+func ResetFetchFailures() {
+	failuresMu.Lock()
+	defer failuresMu.Unlock()
+	fetchFailures = nil
+}
+
+// 输出缺失数据的汇总，没有失败时不输出
+func PrintFailureSummary(w io.Writer, failures []FetchFailure) {
+	if len(failures) == 0 {
+		return
+	}
+	var blocks, receipts int
+	for _, f := range failures {
+		if f.TxHash == (common.Hash{}) {
+			blocks++
+		} else {
+			receipts++
+		}
+	}
+	fmt.Fprintf(w, "\n===> Missing data: %d blocks, %d receipts could not be fetched\n", blocks, receipts)
+	for _, f := range failures {
+		if f.TxHash == (common.Hash{}) {
+			fmt.Fprintf(w, "block %d: %v\n", f.BlockNumber, f.Err)
+		} else {
+			fmt.Fprintf(w, "block %d receipt %s: %v\n", f.BlockNumber, f.TxHash.Hex(), f.Err)
+		}
+	}
+}
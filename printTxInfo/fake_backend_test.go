@@ -0,0 +1,124 @@
+package printTxInfo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// 基于固定区块和回执的内存 Backend，用于测试
+type FakeBackend struct {
+	mu       sync.RWMutex
+	chainID  *big.Int
+	blocks   map[uint64]*types.Block
+	receipts map[common.Hash]*types.Receipt
+	traces   map[common.Hash]json.RawMessage
+	tags     map[rpc.BlockNumber]uint64
+	head     uint64
+}
+
+func NewFakeBackend() *FakeBackend {
+	return &FakeBackend{
+		chainID:  big.NewInt(1337),
+		blocks:   make(map[uint64]*types.Block),
+		receipts: make(map[common.Hash]*types.Receipt),
+		traces:   make(map[common.Hash]json.RawMessage),
+		tags:     make(map[rpc.BlockNumber]uint64),
+	}
+}
+
+// 添加区块及其回执，回执按交易顺序补全 TxHash、BlockHash、BlockNumber 和 TransactionIndex
+func (b *FakeBackend) AddBlock(block *types.Block, receipts []*types.Receipt) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	number := block.NumberU64()
+	b.blocks[number] = block
+	if number > b.head {
+		b.head = number
+	}
+	for i, tx := range block.Transactions() {
+		if i >= len(receipts) {
+			break
+		}
+		receipt := receipts[i]
+		receipt.TxHash = tx.Hash()
+		receipt.BlockHash = block.Hash()
+		receipt.BlockNumber = new(big.Int).SetUint64(number)
+		receipt.TransactionIndex = uint(i)
+		b.receipts[tx.Hash()] = receipt
+	}
+}
+
+func (b *FakeBackend) SetTrace(txHash common.Hash, trace json.RawMessage) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.traces[txHash] = trace
+}
+
+// 设置 safe、finalized 等区块标签对应的区块号，未设置的标签视为最新区块
+func (b *FakeBackend) SetTag(tag rpc.BlockNumber, number uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tags[tag] = number
+}
+
+func (b *FakeBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Set(b.chainID), nil
+}
+
+func (b *FakeBackend) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	n := b.head
+	if number != nil && number.Sign() >= 0 {
+		n = number.Uint64()
+	} else if number != nil {
+		if tagged, ok := b.tags[rpc.BlockNumber(number.Int64())]; ok {
+			n = tagged
+		}
+	}
+	block, ok := b.blocks[n]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return block, nil
+}
+
+func (b *FakeBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	block, err := b.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return block.Header(), nil
+}
+
+func (b *FakeBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	receipt, ok := b.receipts[txHash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
+func (b *FakeBackend) TraceTransaction(ctx context.Context, txHash common.Hash, config map[string]interface{}) (json.RawMessage, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	trace, ok := b.traces[txHash]
+	if !ok {
+		return nil, fmt.Errorf("no trace for tx %s", txHash.Hex())
+	}
+	return trace, nil
+}
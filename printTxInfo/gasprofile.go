@@ -0,0 +1,134 @@
+package printTxInfo
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// callTracer 输出的调用帧
+type callFrame struct {
+	Type         string         `json:"type"`
+	From         common.Address `json:"from"`
+	To           common.Address `json:"to"`
+	Input        hexutil.Bytes  `json:"input"`
+	Output       hexutil.Bytes  `json:"output"`
+	GasUsed      hexutil.Uint64 `json:"gasUsed"`
+	Error        string         `json:"error"`
+	RevertReason string         `json:"revertReason"`
+	Calls        []callFrame    `json:"calls"`
+}
+
+// 一个调用帧的 gas 消耗，GasUsed 包含子调用，SelfGas 为扣除子调用后本帧自身消耗的 gas
+type GasFrame struct {
+	Type    string
+	To      common.Address
+	Func    string
+	Depth   int
+	GasUsed uint64
+	SelfGas uint64
+	Error   string
+	Calls   []*GasFrame
+}
+
+// 使用 callTracer 获取交易的调用树，并计算每个调用帧的 gas 消耗，顶层帧的 GasUsed 与回执一致
+func ProfileGas(ctx context.Context, backend Backend, txHash common.Hash) (*GasFrame, error) {
+	root, err := traceCalls(ctx, backend, txHash)
+	if err != nil {
+		return nil, err
+	}
+	return buildGasFrame(root, 0), nil
+}
+
+func traceCalls(ctx context.Context, backend Backend, txHash common.Hash) (*callFrame, error) {
+	raw, err := backend.TraceTransaction(ctx, txHash, map[string]interface{}{"tracer": "callTracer"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to trace tx %s: %w", txHash.Hex(), err)
+	}
+	var root callFrame
+	if err := json.Unmarshal(raw, &root); err != nil {
+		return nil, fmt.Errorf("invalid callTracer result: %w", err)
+	}
+	return &root, nil
+}
+
+func buildGasFrame(call *callFrame, depth int) *GasFrame {
+	frame := &GasFrame{
+		Type:    call.Type,
+		To:      call.To,
+		Func:    frameFunc(call),
+		Depth:   depth,
+		GasUsed: uint64(call.GasUsed),
+		SelfGas: uint64(call.GasUsed),
+		Error:   call.Error,
+	}
+	for i := range call.Calls {
+		child := buildGasFrame(&call.Calls[i], depth+1)
+		frame.Calls = append(frame.Calls, child)
+		// 子调用的 gas 可能因 63/64 规则和退款超过父帧统计，避免下溢
+		if child.GasUsed > frame.SelfGas {
+			frame.SelfGas = 0
+		} else {
+			frame.SelfGas -= child.GasUsed
+		}
+	}
+	return frame
+}
+
+// 按签名文件解析调用的函数名，找不到时使用 4byte 选择器
+func frameFunc(call *callFrame) string {
+	switch {
+	case call.Type == "CREATE" || call.Type == "CREATE2":
+		return "constructor"
+	case len(call.Input) == 0:
+		return "receive"
+	case len(call.Input) < 4:
+		return "fallback"
+	}
+	selector := "0x" + hex.EncodeToString(call.Input[:4])
+	if sig, ok := signaturesMap[selector]; ok && sig.Type == "function" {
+		return sig.Name
+	}
+	return selector
+}
+
+// 火焰图中的帧名，不含空格和分号
+func (f *GasFrame) label() string {
+	return f.To.Hex() + "." + f.Func
+}
+
+// 以缩进树的形式输出每个调用帧的类型、目标合约、函数和 gas 消耗
+func (f *GasFrame) WriteTree(w io.Writer) {
+	line := fmt.Sprintf("%s%s %s %s gas=%d self=%d", strings.Repeat("  ", f.Depth), f.Type, f.To.Hex(), f.Func, f.GasUsed, f.SelfGas)
+	if f.Error != "" {
+		line += " error=" + f.Error
+	}
+	fmt.Fprintln(w, line)
+	for _, c := range f.Calls {
+		c.WriteTree(w)
+	}
+}
+
+// 以折叠栈格式（frame1;frame2 gas）输出每个调用帧自身消耗的 gas，可直接用于 flamegraph.pl 或 speedscope
+func (f *GasFrame) WriteFolded(w io.Writer) {
+	f.writeFolded(w, "")
+}
+
+func (f *GasFrame) writeFolded(w io.Writer, prefix string) {
+	stack := f.label()
+	if prefix != "" {
+		stack = prefix + ";" + stack
+	}
+	if f.SelfGas > 0 {
+		fmt.Fprintf(w, "%s %d\n", stack, f.SelfGas)
+	}
+	for _, c := range f.Calls {
+		c.writeFolded(w, stack)
+	}
+}
@@ -0,0 +1,59 @@
+package printTxInfo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestProfileGas(t *testing.T) {
+	defer func(m map[string]Signature) { signaturesMap = m }(signaturesMap)
+	signaturesMap = map[string]Signature{
+		"0xa9059cbb": {Type: "function", Name: "transfer(address,uint256)", Signature: "0xa9059cbb"},
+	}
+
+	trace := `{
+	  "type": "CALL", "from": "0x0000000000000000000000000000000000000001", "to": "0x00000000000000000000000000000000000000aa",
+	  "input": "0x12345678", "gasUsed": "0x7530",
+	  "calls": [
+	    {"type": "CALL", "from": "0x00000000000000000000000000000000000000aa", "to": "0x00000000000000000000000000000000000000bb",
+	     "input": "0xa9059cbb0000", "gasUsed": "0x2710",
+	     "calls": [{"type": "STATICCALL", "from": "0x00000000000000000000000000000000000000bb", "to": "0x00000000000000000000000000000000000000cc", "input": "0x", "gasUsed": "0x3e8"}]},
+	    {"type": "CREATE", "from": "0x00000000000000000000000000000000000000aa", "to": "0x00000000000000000000000000000000000000dd",
+	     "input": "0x6080", "gasUsed": "0x1388", "error": "execution reverted"}
+	  ]
+	}`
+	backend := NewFakeBackend()
+	txHash := common.HexToHash("0x01")
+	backend.SetTrace(txHash, json.RawMessage(trace))
+
+	root, err := ProfileGas(context.Background(), backend, txHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tree bytes.Buffer
+	root.WriteTree(&tree)
+	wantTree := `CALL 0x00000000000000000000000000000000000000AA 0x12345678 gas=30000 self=15000
+  CALL 0x00000000000000000000000000000000000000bb transfer(address,uint256) gas=10000 self=9000
+    STATICCALL 0x00000000000000000000000000000000000000cc receive gas=1000 self=1000
+  CREATE 0x00000000000000000000000000000000000000dd constructor gas=5000 self=5000 error=execution reverted
+`
+	if tree.String() != wantTree {
+		t.Errorf("tree:\n%s\nwant:\n%s", tree.String(), wantTree)
+	}
+
+	var folded bytes.Buffer
+	root.WriteFolded(&folded)
+	wantFolded := `0x00000000000000000000000000000000000000AA.0x12345678 15000
+0x00000000000000000000000000000000000000AA.0x12345678;0x00000000000000000000000000000000000000bb.transfer(address,uint256) 9000
+0x00000000000000000000000000000000000000AA.0x12345678;0x00000000000000000000000000000000000000bb.transfer(address,uint256);0x00000000000000000000000000000000000000cc.receive 1000
+0x00000000000000000000000000000000000000AA.0x12345678;0x00000000000000000000000000000000000000dd.constructor 5000
+`
+	if folded.String() != wantFolded {
+		t.Errorf("folded:\n%s\nwant:\n%s", folded.String(), wantFolded)
+	}
+}
@@ -0,0 +1,203 @@
+package printTxInfo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// 交易费用明细，Burnt 包含执行 gas 和 blob gas 两部分的基础费用
+type FeeBreakdown struct {
+	BaseFee           *big.Int // 区块基础费用，London 之前为 nil
+	EffectiveGasPrice *big.Int
+	PriorityFee       *big.Int // 每单位 gas 支付给出块者的小费
+	ExecutionFee      *big.Int // GasUsed * EffectiveGasPrice
+	BlobFee           *big.Int // BlobGasUsed * BlobGasPrice
+	Burnt             *big.Int
+	Tip               *big.Int
+	Total             *big.Int
+}
+
+// 单笔交易的完整信息
+type Inspection struct {
+	Tx          *types.Transaction
+	Receipt     *types.Receipt
+	TxData      map[string]interface{}
+	ReceiptData map[string]interface{}
+
+	Func         string // 签名文件中没有对应函数时为空
+	Args         []DecodedArg
+	CallError    error // calldata 解码失败的原因，此时只输出原始 calldata
+	Logs         []*DecodedLog
+	Transfers    Transfers
+	Fee          FeeBreakdown
+	RevertReason string // 仅失败交易，节点不支持 debug_traceTransaction 时为空
+}
+
+// 通过回执定位区块，取出交易并解码参数、日志、代币转移、费用明细和失败原因
+func InspectTx(ctx context.Context, backend Backend, txHash common.Hash) (*Inspection, error) {
+	receipt, err := backend.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receipt for tx %s: %w", txHash.Hex(), err)
+	}
+	block, err := backend.BlockByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block %d: %w", receipt.BlockNumber, err)
+	}
+	txs := block.Transactions()
+	if int(receipt.TransactionIndex) >= len(txs) || txs[receipt.TransactionIndex].Hash() != txHash {
+		return nil, fmt.Errorf("tx %s not found in block %d, the block may have been reorged", txHash.Hex(), receipt.BlockNumber)
+	}
+	tx := txs[receipt.TransactionIndex]
+
+	ins := &Inspection{
+		Tx:          tx,
+		Receipt:     receipt,
+		TxData:      extractTxData(tx),
+		ReceiptData: extractReceiptData(receipt),
+		Transfers:   ExtractTransfers(ctx, receipt.Logs),
+		Fee:         feeBreakdown(block.BaseFee(), receipt),
+	}
+
+	// 解码失败时保留原始数据并记录错误，不影响其余部分的输出
+	name, args, ok, err := DecodeCalldata(tx.Data())
+	if ok {
+		ins.Func, ins.Args, ins.CallError = name, args, err
+	}
+	for _, l := range receipt.Logs {
+		decoded, err := DecodeLog(l)
+		decoded.Err = err
+		ins.Logs = append(ins.Logs, decoded)
+	}
+
+	if receipt.Status == types.ReceiptStatusFailed {
+		if root, err := traceCalls(ctx, backend, txHash); err == nil {
+			ins.RevertReason = revertReason(root)
+		}
+	}
+	return ins, nil
+}
+
+func feeBreakdown(baseFee *big.Int, receipt *types.Receipt) FeeBreakdown {
+	gasUsed := new(big.Int).SetUint64(receipt.GasUsed)
+	fee := FeeBreakdown{
+		BaseFee:           baseFee,
+		EffectiveGasPrice: receipt.EffectiveGasPrice,
+		ExecutionFee:      new(big.Int),
+		BlobFee:           new(big.Int),
+		Burnt:             new(big.Int),
+		Tip:               new(big.Int),
+		Total:             txFee(receipt),
+	}
+	if receipt.EffectiveGasPrice != nil {
+		fee.ExecutionFee.Mul(gasUsed, receipt.EffectiveGasPrice)
+		fee.PriorityFee = new(big.Int).Set(receipt.EffectiveGasPrice)
+		if baseFee != nil {
+			fee.PriorityFee.Sub(fee.PriorityFee, baseFee)
+			fee.Burnt.Mul(gasUsed, baseFee)
+		}
+		fee.Tip.Mul(gasUsed, fee.PriorityFee)
+	}
+	if receipt.BlobGasPrice != nil {
+		fee.BlobFee.Mul(new(big.Int).SetUint64(receipt.BlobGasUsed), receipt.BlobGasPrice)
+		fee.Burnt.Add(fee.Burnt, fee.BlobFee)
+	}
+	return fee
+}
+
+// 优先使用 callTracer 给出的 revertReason，否则按 Error(string) 解码返回数据，都没有时返回执行错误
+func revertReason(root *callFrame) string {
+	if root.RevertReason != "" {
+		return root.RevertReason
+	}
+	if reason, err := abi.UnpackRevert(root.Output); err == nil {
+		return reason
+	}
+	if len(root.Output) > 0 {
+		return fmt.Sprintf("%s (output %s)", root.Error, root.Output)
+	}
+	return root.Error
+}
+
+// 输出交易和回执的所有字段（按字段名排序）、解码后的参数、日志、代币转移、费用明细和失败原因
+func FprintInspection(w io.Writer, ins *Inspection) {
+	fmt.Fprintln(w, "===> Transaction:")
+	printFields(w, ins.TxData)
+	fmt.Fprintln(w, "===> Receipt:")
+	printFields(w, ins.ReceiptData)
+
+	if ins.Func != "" {
+		fmt.Fprintf(w, "===> Call: %s\n", ins.Func)
+		if ins.CallError != nil {
+			fmt.Fprintf(w, "  decode error: %v\n  calldata: 0x%x\n", ins.CallError, ins.Tx.Data())
+		}
+		for _, arg := range ins.Args {
+			fmt.Fprintf(w, "  %s %s: %s\n", arg.Type, arg.Name, arg.Value)
+		}
+	}
+
+	fmt.Fprintf(w, "===> Logs (%d):\n", len(ins.Logs))
+	for _, l := range ins.Logs {
+		if l.Event == "" || l.Err != nil {
+			fmt.Fprintf(w, "  #%d %s topics=%v data=0x%x\n", l.Index, l.Address.Hex(), l.Log.Topics, l.Log.Data)
+			if l.Err != nil {
+				fmt.Fprintf(w, "    decode error: %v\n", l.Err)
+			}
+			continue
+		}
+		fmt.Fprintf(w, "  #%d %s %s\n", l.Index, l.Address.Hex(), l.Event)
+		for _, arg := range l.Args {
+			indexed := ""
+			if arg.Indexed {
+				indexed = " indexed"
+			}
+			fmt.Fprintf(w, "    %s%s %s: %s\n", arg.Type, indexed, arg.Name, arg.Value)
+		}
+	}
+
+	if len(ins.Transfers) > 0 {
+		fmt.Fprintf(w, "===> Transfers (%d):\n", len(ins.Transfers))
+		for _, t := range ins.Transfers {
+			fmt.Fprintf(w, "  %s\n", t)
+		}
+	}
+
+	fee := ins.Fee
+	fmt.Fprintln(w, "===> Fee:")
+	fmt.Fprintf(w, "  BaseFee: %v\n", fee.BaseFee)
+	fmt.Fprintf(w, "  EffectiveGasPrice: %v\n", fee.EffectiveGasPrice)
+	fmt.Fprintf(w, "  PriorityFee: %v\n", fee.PriorityFee)
+	fmt.Fprintf(w, "  ExecutionFee: %s\n", fee.ExecutionFee)
+	fmt.Fprintf(w, "  BlobFee: %s\n", fee.BlobFee)
+	fmt.Fprintf(w, "  Burnt: %s\n", fee.Burnt)
+	fmt.Fprintf(w, "  Tip: %s\n", fee.Tip)
+	fmt.Fprintf(w, "  Total: %s (%s ETH)\n", fee.Total, FormatUnits(fee.Total, 18))
+
+	if ins.Receipt.Status == types.ReceiptStatusFailed {
+		reason := ins.RevertReason
+		if reason == "" {
+			reason = "unavailable"
+		}
+		fmt.Fprintf(w, "===> Revert reason: %s\n", reason)
+	}
+}
+
+// 日志在 Logs 部分单独解码输出，这里跳过原始日志
+func printFields(w io.Writer, fields map[string]interface{}) {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		if key != "Logs" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "  %s: %v\n", key, fields[key])
+	}
+}
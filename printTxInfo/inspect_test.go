@@ -0,0 +1,148 @@
+package printTxInfo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestInspectTx(t *testing.T) {
+	defer func(m map[string]Signature) { signaturesMap = m }(signaturesMap)
+	signaturesMap = map[string]Signature{
+		"0x40c10f19": {
+			Type: "function", Name: "mint(address,uint256)", Signature: "0x40c10f19",
+			Inputs: json.RawMessage(`[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}]`),
+		},
+		transferTopic.Hex(): {
+			Type: "event", Name: "Transfer(address,address,uint256)", Signature: transferTopic.Hex(),
+			Inputs: json.RawMessage(`[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]`),
+		},
+	}
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mintData := append(hexutil.MustDecode("0x40c10f19"), common.LeftPadBytes(testOther.Bytes(), 32)...)
+	mintData = append(mintData, common.LeftPadBytes(big.NewInt(500).Bytes(), 32)...)
+	mint := signTestTx(t, key, &types.DynamicFeeTx{ChainID: testChainID, Nonce: 0, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(10), Gas: 60000, To: &testContract, Data: mintData})
+	create := signTestTx(t, key, &types.DynamicFeeTx{ChainID: testChainID, Nonce: 1, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(10), Gas: 100000, Data: []byte{0x60, 0x80}})
+
+	header := &types.Header{Number: big.NewInt(1), Time: 1012, BaseFee: big.NewInt(7)}
+	block := types.NewBlockWithHeader(header).WithBody(types.Body{Transactions: []*types.Transaction{mint, create}})
+	transferLog := &types.Log{
+		Address: testContract,
+		Topics:  []common.Hash{transferTopic, {}, common.BytesToHash(testOther.Bytes())},
+		Data:    common.LeftPadBytes(big.NewInt(500).Bytes(), 32),
+	}
+	// ERC-721 Transfer 的 tokenId 也是 indexed，签名文件中的 ERC-20 事件无法解码
+	nftLog := &types.Log{
+		Address: testOther,
+		Topics:  []common.Hash{transferTopic, {}, common.BytesToHash(testOther.Bytes()), common.BigToHash(big.NewInt(7))},
+		Index:   1,
+	}
+	backend := NewFakeBackend()
+	backend.AddBlock(block, []*types.Receipt{
+		{Status: types.ReceiptStatusSuccessful, GasUsed: 50000, EffectiveGasPrice: big.NewInt(8), Logs: []*types.Log{transferLog, nftLog}},
+		{Status: types.ReceiptStatusFailed, GasUsed: 30000, EffectiveGasPrice: big.NewInt(8)},
+	})
+	// Error("no") 的 ABI 编码
+	backend.SetTrace(create.Hash(), json.RawMessage(`{"type":"CREATE","error":"execution reverted","gasUsed":"0x7530",
+	  "output":"0x08c379a000000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000002`+
+		`6e6f000000000000000000000000000000000000000000000000000000000000"}`))
+
+	ins, err := InspectTx(context.Background(), backend, mint.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ins.Func != "mint(address,uint256)" || len(ins.Args) != 2 || ins.Args[0].Value != testOther.Hex() || ins.Args[1].Value != "500" {
+		t.Errorf("call = %s %+v", ins.Func, ins.Args)
+	}
+	if len(ins.Logs) != 2 || ins.Logs[0].Event != "Transfer(address,address,uint256)" || len(ins.Logs[0].Args) != 3 || ins.Logs[0].Err != nil {
+		t.Fatalf("logs = %+v", ins.Logs)
+	}
+	if ins.Logs[1].Err == nil || len(ins.Logs[1].Args) != 0 || ins.Logs[1].Log != nftLog {
+		t.Errorf("undecodable log = %+v", ins.Logs[1])
+	}
+	if len(ins.Transfers) != 2 || ins.Transfers[0].Amount.Int64() != 500 || ins.Transfers[1].TokenID.Int64() != 7 {
+		t.Errorf("transfers = %v", ins.Transfers)
+	}
+	var mintOut bytes.Buffer
+	FprintInspection(&mintOut, ins)
+	if !strings.Contains(mintOut.String(), "decode error: event Transfer(address,address,uint256) expects 2 indexed topics, got 3") {
+		t.Errorf("output missing log decode error:\n%s", mintOut.String())
+	}
+	fee := ins.Fee
+	if fee.PriorityFee.Int64() != 1 || fee.Burnt.Int64() != 350000 || fee.Tip.Int64() != 50000 || fee.Total.Int64() != 400000 {
+		t.Errorf("fee = %+v", fee)
+	}
+	if ins.RevertReason != "" {
+		t.Errorf("successful tx has revert reason %q", ins.RevertReason)
+	}
+
+	ins, err = InspectTx(context.Background(), backend, create.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ins.RevertReason != "no" {
+		t.Errorf("revert reason = %q, want no", ins.RevertReason)
+	}
+	if ins.TxData["To"] != "" {
+		t.Errorf("contract creation To = %v", ins.TxData["To"])
+	}
+
+	var out bytes.Buffer
+	FprintInspection(&out, ins)
+	for _, want := range []string{"===> Transaction:", "  Hash: " + create.Hash().Hex(), "  Status: 0", "  Total: 240000", "===> Revert reason: no"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	if _, err := InspectTx(context.Background(), backend, common.HexToHash("0x02")); err == nil {
+		t.Error("expected error for unknown tx")
+	}
+}
+
+func TestDecodeLogPositional(t *testing.T) {
+	defer func(m map[string]Signature) { signaturesMap = m }(signaturesMap)
+	topic := crypto.Keccak256Hash([]byte("Swap(address,uint256,uint256,uint256)"))
+	// 未命名参数和同名参数都按位置解码
+	signaturesMap = map[string]Signature{
+		topic.Hex(): {
+			Type: "event", Name: "Swap(address,uint256,uint256,uint256)", Signature: topic.Hex(),
+			Inputs: json.RawMessage(`[{"name":"","type":"address","indexed":true},{"name":"","type":"uint256"},` +
+				`{"name":"amount","type":"uint256"},{"name":"amount","type":"uint256","indexed":true}]`),
+		},
+	}
+	data := append(common.LeftPadBytes(big.NewInt(1).Bytes(), 32), common.LeftPadBytes(big.NewInt(2).Bytes(), 32)...)
+	decoded, err := DecodeLog(&types.Log{
+		Topics: []common.Hash{topic, common.BytesToHash(testOther.Bytes()), common.BigToHash(big.NewInt(3))},
+		Data:   data,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DecodedArg{
+		{Name: "arg0", Type: "address", Indexed: true, Value: testOther.Hex()},
+		{Name: "arg1", Type: "uint256", Value: "1"},
+		{Name: "amount", Type: "uint256", Value: "2"},
+		{Name: "amount", Type: "uint256", Indexed: true, Value: "3"},
+	}
+	if len(decoded.Args) != len(want) {
+		t.Fatalf("args = %+v, want %+v", decoded.Args, want)
+	}
+	for i := range want {
+		if decoded.Args[i] != want[i] {
+			t.Errorf("arg %d = %+v, want %+v", i, decoded.Args[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package printTxInfo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"golang.org/x/time/rate"
+)
+
+// 多节点的路由策略
+const (
+	RouteRoundRobin = "roundrobin" // 在健康节点间轮询
+	RouteLatency    = "latency"    // 按平均延迟的倒数加权随机选择
+)
+
+type Endpoint struct {
+	URL     string
+	backend Backend
+	limiter *rate.Limiter
+
+	mu       sync.Mutex
+	healthy  bool
+	latency  time.Duration // 延迟的指数移动平均
+	requests uint64
+	errors   uint64
+	total    time.Duration
+}
+
+// rps 为 0 表示不限速
+func NewEndpoint(url string, backend Backend, rps float64) *Endpoint {
+	ep := &Endpoint{URL: url, backend: backend, healthy: true}
+	if rps > 0 {
+		ep.limiter = rate.NewLimiter(rate.Limit(rps), max(1, int(rps)))
+	}
+	return ep
+}
+
+// 解析 url#rps 形式的节点配置，例如 https://rpc.example.org#20
+func ParseEndpointSpec(spec string) (string, float64, error) {
+	i := strings.LastIndex(spec, "#")
+	if i < 0 {
+		return spec, 0, nil
+	}
+	rps, err := strconv.ParseFloat(spec[i+1:], 64)
+	if err != nil || rps < 0 {
+		return "", 0, fmt.Errorf("invalid rate limit in endpoint %q", spec)
+	}
+	return spec[:i], rps, nil
+}
+
+func (ep *Endpoint) Healthy() bool {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	return ep.healthy
+}
+
+func (ep *Endpoint) setHealthy(healthy bool) {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	if ep.healthy != healthy {
+		log.Printf("Endpoint %s is now %s", ep.URL, map[bool]string{true: "healthy", false: "unhealthy"}[healthy])
+	}
+	ep.healthy = healthy
+}
+
+func (ep *Endpoint) observe(latency time.Duration, err error) {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+
+	ep.requests++
+	ep.total += latency
+	if ep.latency == 0 {
+		ep.latency = latency
+	} else {
+		ep.latency = (ep.latency*4 + latency) / 5
+	}
+	if err != nil && IsRetryable(err) {
+		ep.errors++
+		ep.healthy = false
+	}
+}
+
+func (ep *Endpoint) weight() float64 {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	return 1 / float64(ep.latency+time.Millisecond)
+}
+
+type EndpointStats struct {
+	URL        string
+	Healthy    bool
+	Requests   uint64
+	Errors     uint64
+	AvgLatency time.Duration
+}
+
+func (ep *Endpoint) Stats() EndpointStats {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	stats := EndpointStats{URL: ep.URL, Healthy: ep.healthy, Requests: ep.requests, Errors: ep.errors}
+	if ep.requests > 0 {
+		stats.AvgLatency = ep.total / time.Duration(ep.requests)
+	}
+	return stats
+}
+
+// 在多个节点之间分发请求的 Backend，节点返回临时错误时标记为不健康并切换到下一个节点
+type PoolBackend struct {
+	endpoints []*Endpoint
+	strategy  string
+	next      uint64
+}
+
+func NewPoolBackend(endpoints []*Endpoint, strategy string) (*PoolBackend, error) {
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("no RPC endpoints configured")
+	}
+	switch strategy {
+	case RouteRoundRobin, RouteLatency:
+	default:
+		return nil, fmt.Errorf("unknown routing strategy %q, expected %s or %s", strategy, RouteRoundRobin, RouteLatency)
+	}
+	return &PoolBackend{endpoints: endpoints, strategy: strategy}, nil
+}
+
+// 连接逗号分隔的节点列表，每个节点可用 url#rps 单独限速
+func DialPool(ctx context.Context, specs string, strategy string) (*PoolBackend, error) {
+	var endpoints []*Endpoint
+	for _, spec := range strings.Split(specs, ",") {
+		url, rps, err := ParseEndpointSpec(strings.TrimSpace(spec))
+		if err != nil {
+			return nil, err
+		}
+		client, err := ethclient.DialContext(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", url, err)
+		}
+		backend := NewRPCBackend(client)
+		backend.URL = url
+		endpoints = append(endpoints, NewEndpoint(url, backend, rps))
+	}
+	return NewPoolBackend(endpoints, strategy)
+}
+
+// 选择一个未尝试过的节点，优先选择健康节点；全部不健康时仍会尝试
+func (p *PoolBackend) pick(tried map[*Endpoint]bool) *Endpoint {
+	var healthy, rest []*Endpoint
+	for _, ep := range p.endpoints {
+		if tried[ep] {
+			continue
+		}
+		if ep.Healthy() {
+			healthy = append(healthy, ep)
+		} else {
+			rest = append(rest, ep)
+		}
+	}
+	candidates := healthy
+	if len(candidates) == 0 {
+		candidates = rest
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	if p.strategy == RouteLatency {
+		weights := make([]float64, len(candidates))
+		var sum float64
+		for i, ep := range candidates {
+			weights[i] = ep.weight()
+			sum += weights[i]
+		}
+		r := rand.Float64() * sum
+		for i, w := range weights {
+			if r < w {
+				return candidates[i]
+			}
+			r -= w
+		}
+		return candidates[len(candidates)-1]
+	}
+	return candidates[atomic.AddUint64(&p.next, 1)%uint64(len(candidates))]
+}
+
+func (p *PoolBackend) do(ctx context.Context, op string, fn func(Backend) error) error {
+	var (
+		tried   = make(map[*Endpoint]bool)
+		lastErr error
+	)
+	for ep := p.pick(tried); ep != nil; ep = p.pick(tried) {
+		if ep.limiter != nil {
+			if err := ep.limiter.Wait(ctx); err != nil {
+				return err
+			}
+		}
+		start := time.Now()
+		err := fn(ep.backend)
+		ep.observe(time.Since(start), err)
+		if err == nil || !IsRetryable(err) {
+			return err
+		}
+		tried[ep] = true
+		lastErr = err
+		if len(tried) < len(p.endpoints) {
+			log.Printf("%s failed on %s, failing over: %v", op, ep.URL, err)
+		}
+	}
+	return lastErr
+}
+
+// 检查所有节点能否返回最新区块头，更新健康状态
+func (p *PoolBackend) CheckHealth(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, ep := range p.endpoints {
+		wg.Add(1)
+		go func(ep *Endpoint) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+			_, err := ep.backend.HeaderByNumber(ctx, nil)
+			ep.setHealthy(err == nil)
+		}(ep)
+	}
+	wg.Wait()
+}
+
+// 每隔 interval 做一次健康检查，直到 ctx 结束
+func (p *PoolBackend) StartHealthChecks(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.CheckHealth(ctx)
+			}
+		}
+	}()
+}
+
+func (p *PoolBackend) Stats() []EndpointStats {
+	stats := make([]EndpointStats, len(p.endpoints))
+	for i, ep := range p.endpoints {
+		stats[i] = ep.Stats()
+	}
+	return stats
+}
+
+func PrintPoolStats(w io.Writer, stats []EndpointStats) {
+	fmt.Fprintf(w, "\n===> RPC Endpoints:\n")
+	for _, s := range stats {
+		status := "healthy"
+		if !s.Healthy {
+			status = "unhealthy"
+		}
+		fmt.Fprintf(w, "%s: %s, requests %d, errors %d, avg latency %v\n", s.URL, status, s.Requests, s.Errors, s.AvgLatency.Round(time.Millisecond))
+	}
+}
+
+func (p *PoolBackend) ChainID(ctx context.Context) (chainID *big.Int, err error) {
+	err = p.do(ctx, "eth_chainId", func(b Backend) error {
+		chainID, err = b.ChainID(ctx)
+		return err
+	})
+	return chainID, err
+}
+
+func (p *PoolBackend) StreamTraceTransaction(ctx context.Context, txHash common.Hash, config map[string]interface{}) (body io.ReadCloser, err error) {
+	err = p.do(ctx, "debug_traceTransaction", func(b Backend) error {
+		body, err = streamTrace(ctx, b, txHash, config)
+		return err
+	})
+	return body, err
+}
+
+// 供 EnableTokenInfo 等需要 eth_call 的功能使用
+func (p *PoolBackend) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) (out []byte, err error) {
+	err = p.do(ctx, "eth_call", func(b Backend) error {
+		caller, ok := b.(ethereum.ContractCaller)
+		if !ok {
+			return fmt.Errorf("endpoint does not support eth_call")
+		}
+		out, err = caller.CallContract(ctx, msg, blockNumber)
+		return err
+	})
+	return out, err
+}
+
+func (p *PoolBackend) BlockByNumber(ctx context.Context, number *big.Int) (block *types.Block, err error) {
+	err = p.do(ctx, "eth_getBlockByNumber", func(b Backend) error {
+		block, err = b.BlockByNumber(ctx, number)
+		return err
+	})
+	return block, err
+}
+
+func (p *PoolBackend) HeaderByNumber(ctx context.Context, number *big.Int) (header *types.Header, err error) {
+	err = p.do(ctx, "eth_getHeaderByNumber", func(b Backend) error {
+		header, err = b.HeaderByNumber(ctx, number)
+		return err
+	})
+	return header, err
+}
+
+func (p *PoolBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (receipt *types.Receipt, err error) {
+	err = p.do(ctx, "eth_getTransactionReceipt", func(b Backend) error {
+		receipt, err = b.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return receipt, err
+}
+
+func (p *PoolBackend) TraceTransaction(ctx context.Context, txHash common.Hash, config map[string]interface{}) (result json.RawMessage, err error) {
+	err = p.do(ctx, "debug_traceTransaction", func(b Backend) error {
+		result, err = b.TraceTransaction(ctx, txHash, config)
+		return err
+	})
+	return result, err
+}
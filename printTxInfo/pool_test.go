@@ -0,0 +1,89 @@
+package printTxInfo
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// 所有请求都返回 503 的节点
+type downBackend struct {
+	Backend
+}
+
+func (downBackend) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	return nil, rpc.HTTPError{StatusCode: 503, Status: "503 Service Unavailable"}
+}
+
+func (downBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return nil, rpc.HTTPError{StatusCode: 503, Status: "503 Service Unavailable"}
+}
+
+func TestParseEndpointSpec(t *testing.T) {
+	url, rps, err := ParseEndpointSpec("https://rpc.example.org/v1?key=abc#12.5")
+	if err != nil || url != "https://rpc.example.org/v1?key=abc" || rps != 12.5 {
+		t.Fatalf("got %q %v %v", url, rps, err)
+	}
+	if url, rps, err = ParseEndpointSpec("http://127.0.0.1:8545"); err != nil || url != "http://127.0.0.1:8545" || rps != 0 {
+		t.Fatalf("got %q %v %v", url, rps, err)
+	}
+	if _, _, err = ParseEndpointSpec("http://127.0.0.1:8545#fast"); err == nil {
+		t.Fatal("expected error for invalid rate limit")
+	}
+}
+
+func TestPoolRoundRobin(t *testing.T) {
+	backend := newTestBackend(t)
+	a, b := NewEndpoint("a", backend, 0), NewEndpoint("b", backend, 0)
+	pool, err := NewPoolBackend([]*Endpoint{a, b}, RouteRoundRobin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if _, err := pool.BlockByNumber(context.Background(), big.NewInt(1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if a.Stats().Requests != 5 || b.Stats().Requests != 5 {
+		t.Fatalf("requests not balanced: %+v", pool.Stats())
+	}
+}
+
+func TestPoolFailover(t *testing.T) {
+	backend := newTestBackend(t)
+	down, up := NewEndpoint("down", downBackend{backend}, 0), NewEndpoint("up", backend, 0)
+	for _, strategy := range []string{RouteRoundRobin, RouteLatency} {
+		pool, err := NewPoolBackend([]*Endpoint{down, up}, strategy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 4; i++ {
+			if _, err := pool.BlockByNumber(context.Background(), big.NewInt(1)); err != nil {
+				t.Fatalf("%s: %v", strategy, err)
+			}
+		}
+		// 永久错误不触发切换
+		if _, err := pool.BlockByNumber(context.Background(), big.NewInt(9)); err != ethereum.NotFound {
+			t.Fatalf("%s: got %v, want not found", strategy, err)
+		}
+	}
+
+	// 失败节点只被尝试一次，之后被标记为不健康
+	if stats := down.Stats(); stats.Healthy || stats.Requests != 1 || stats.Errors != 1 {
+		t.Fatalf("unexpected stats for failed endpoint: %+v", stats)
+	}
+	if stats := up.Stats(); !stats.Healthy || stats.Requests != 10 {
+		t.Fatalf("unexpected stats for healthy endpoint: %+v", stats)
+	}
+
+	// 健康检查后失败节点仍不健康，正常节点保持健康
+	pool, _ := NewPoolBackend([]*Endpoint{down, up}, RouteRoundRobin)
+	pool.CheckHealth(context.Background())
+	if down.Healthy() || !up.Healthy() {
+		t.Fatalf("unexpected health after check: %+v", pool.Stats())
+	}
+}
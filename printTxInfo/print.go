@@ -1,16 +1,24 @@
 package printTxInfo
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"os"
+)
 
 func PrintTxInfo(txData, receiptData map[string]interface{}, queryKeys []string) {
-	fmt.Println("\n===> Queried Fields:")
+	FprintTxInfo(os.Stdout, txData, receiptData, queryKeys)
+}
+
+func FprintTxInfo(w io.Writer, txData, receiptData map[string]interface{}, queryKeys []string) {
+	fmt.Fprintln(w, "\n===> Queried Fields:")
 	for _, queryKey := range queryKeys {
 		if value, ok := txData[queryKey]; ok {
-			fmt.Printf("Transaction %s: %v\n", queryKey, value)
+			fmt.Fprintf(w, "Transaction %s: %v\n", queryKey, value)
 		} else if value, ok := receiptData[queryKey]; ok {
-			fmt.Printf("Receipt %s: %v\n", queryKey, value)
+			fmt.Fprintf(w, "Receipt %s: %v\n", queryKey, value)
 		} else {
-			fmt.Printf("Unknown query key: %s\n", queryKey)
+			fmt.Fprintf(w, "Unknown query key: %s\n", queryKey)
 		}
 	}
 
@@ -5,22 +5,28 @@ import (
 	"encoding/hex"
 	"log"
 	"math/big"
+	"sort"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 )
 
-func ProcessBlock(ctx context.Context, client *ethclient.Client, blockNumber *big.Int, contractAddr common.Address, calldataPrefix string, statusFilter uint64, queryKeys []string, results chan<- TxInfo) {
+func ProcessBlock(ctx context.Context, client Backend, blockNumber *big.Int, contractAddr common.Address, calldataPrefix string, statusFilter uint64, txTypes []uint8, queryKeys []string, results chan<- TxInfo) {
 	block, err := client.BlockByNumber(ctx, blockNumber)
 	if err != nil {
 		log.Printf("Failed to get block %d: %v", blockNumber, err)
+		recordFailure(FetchFailure{BlockNumber: blockNumber.Uint64(), Err: err})
 		return
 	}
 
 	for _, tx := range block.Transactions() {
 		to := tx.To()
 		if to != nil && *to == contractAddr {
+			// 过滤交易类型
+			if !matchTxType(tx.Type(), txTypes) {
+				continue
+			}
+
 			// 检查calldata前10位
 			if calldataPrefix != "" {
 				data := tx.Data()
@@ -33,6 +39,7 @@ func ProcessBlock(ctx context.Context, client *ethclient.Client, blockNumber *bi
 			receipt, err := client.TransactionReceipt(ctx, tx.Hash())
 			if err != nil {
 				log.Printf("Failed to get receipt for tx %s: %v", tx.Hash().Hex(), err)
+				recordFailure(FetchFailure{BlockNumber: block.NumberU64(), TxHash: tx.Hash(), Err: err})
 				continue
 			}
 
@@ -42,13 +49,65 @@ func ProcessBlock(ctx context.Context, client *ethclient.Client, blockNumber *bi
 			}
 
 			txData := extractTxData(tx)
+			if beaconClient != nil && len(tx.BlobHashes()) > 0 {
+				blobFields, err := extractBlobData(ctx, block, tx)
+				if err != nil {
+					log.Printf("Failed to get blobs for tx %s: %v", tx.Hash().Hex(), err)
+				}
+				for key, value := range blobFields {
+					txData[key] = value
+				}
+			}
 			receiptData := extractReceiptData(receipt)
+			if containsKey(queryKeys, "transfers") {
+				receiptData["transfers"] = ExtractTransfers(ctx, receipt.Logs)
+			}
+			if containsKey(queryKeys, "stateDiff") {
+				diff, err := TraceStateDiff(ctx, client, tx.Hash())
+				if err != nil {
+					log.Printf("Failed to get state diff for tx %s: %v", tx.Hash().Hex(), err)
+				}
+				receiptData["stateDiff"] = diff
+			}
 			results <- TxInfo{
 				BlockNumber:      receipt.BlockNumber.Uint64(),
 				TransactionIndex: receipt.TransactionIndex,
 				TxData:           txData,
 				ReceiptData:      receiptData,
+				Tx:               tx,
+				Receipt:          receipt,
 			}
 		}
 	}
 }
+
+func containsKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func matchTxType(txType uint8, txTypes []uint8) bool {
+	if len(txTypes) == 0 {
+		return true
+	}
+	for _, t := range txTypes {
+		if t == txType {
+			return true
+		}
+	}
+	return false
+}
+
+// 按区块号和交易索引排序
+func SortTxInfos(infos []TxInfo) {
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].BlockNumber == infos[j].BlockNumber {
+			return infos[i].TransactionIndex < infos[j].TransactionIndex
+		}
+		return infos[i].BlockNumber < infos[j].BlockNumber
+	})
+}
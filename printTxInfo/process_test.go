@@ -0,0 +1,223 @@
+package printTxInfo
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"os/exec"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+var (
+	testChainID  = big.NewInt(1337)
+	testContract = common.HexToAddress("0xcf7ed3acca5a467e9e704c703e8d87f634fb0fc9")
+	testOther    = common.HexToAddress("0x000000000000000000000000000000000000dead")
+	testBlobHash = common.HexToHash("0x01f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0")
+
+	transferData = hexutil.MustDecode("0xa9059cbb000000000000000000000000000000000000000000000000000000000000dead")
+	approveData  = hexutil.MustDecode("0x095ea7b3000000000000000000000000000000000000000000000000000000000000beef")
+)
+
+func signTestTx(t *testing.T, key *ecdsa.PrivateKey, inner types.TxData) *types.Transaction {
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(testChainID), inner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func testBlock(number uint64, txs ...*types.Transaction) *types.Block {
+	header := &types.Header{Number: new(big.Int).SetUint64(number), Time: 1000 + number*12}
+	return types.NewBlockWithHeader(header).WithBody(types.Body{Transactions: txs})
+}
+
+// 区块 1：成功的 transfer、发往其他地址的交易、失败的 approve
+// 区块 2：成功的 blob 交易、成功的 legacy transfer
+func newTestBackend(t *testing.T) *FakeBackend {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := NewFakeBackend()
+
+	backend.AddBlock(testBlock(1,
+		signTestTx(t, key, &types.DynamicFeeTx{ChainID: testChainID, Nonce: 0, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(10), Gas: 60000, To: &testContract, Data: transferData}),
+		signTestTx(t, key, &types.DynamicFeeTx{ChainID: testChainID, Nonce: 1, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(10), Gas: 21000, To: &testOther}),
+		signTestTx(t, key, &types.DynamicFeeTx{ChainID: testChainID, Nonce: 2, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(10), Gas: 60000, To: &testContract, Data: approveData}),
+	), []*types.Receipt{
+		{Status: types.ReceiptStatusSuccessful, GasUsed: 51000},
+		{Status: types.ReceiptStatusSuccessful, GasUsed: 21000},
+		{Status: types.ReceiptStatusFailed, GasUsed: 23000},
+	})
+
+	backend.AddBlock(testBlock(2,
+		signTestTx(t, key, &types.BlobTx{ChainID: uint256.MustFromBig(testChainID), Nonce: 3, GasTipCap: uint256.NewInt(1), GasFeeCap: uint256.NewInt(10), Gas: 21000, To: testContract, BlobFeeCap: uint256.NewInt(7), BlobHashes: []common.Hash{testBlobHash}}),
+		signTestTx(t, key, &types.LegacyTx{Nonce: 4, GasPrice: big.NewInt(10), Gas: 60000, To: &testContract, Data: transferData}),
+	), []*types.Receipt{
+		{Status: types.ReceiptStatusSuccessful, GasUsed: 21000, BlobGasUsed: 131072, BlobGasPrice: big.NewInt(1)},
+		{Status: types.ReceiptStatusSuccessful, GasUsed: 50000},
+	})
+
+	saved := signaturesMap
+	t.Cleanup(func() { signaturesMap = saved })
+	signaturesMap = map[string]Signature{
+		"0xa9059cbb": {Name: "transfer(address,uint256)", Signature: "0xa9059cbb"},
+	}
+	return backend
+}
+
+type scanFilter struct {
+	calldataPrefix string
+	statusFilter   uint64
+	txTypes        []uint8
+}
+
+// 倒序处理区块，验证排序后结果仍按区块号和交易索引排列
+func runScan(t *testing.T, backend Backend, filter scanFilter) []TxInfo {
+	results := make(chan TxInfo, 100)
+	for number := int64(2); number >= 1; number-- {
+		ProcessBlock(context.Background(), backend, big.NewInt(number), testContract, filter.calldataPrefix, filter.statusFilter, filter.txTypes, nil, results)
+	}
+	close(results)
+
+	var infos []TxInfo
+	for info := range results {
+		infos = append(infos, info)
+	}
+	SortTxInfos(infos)
+	return infos
+}
+
+type txPos struct {
+	block uint64
+	index uint
+}
+
+func positions(infos []TxInfo) []txPos {
+	var pos []txPos
+	for _, info := range infos {
+		pos = append(pos, txPos{info.BlockNumber, info.TransactionIndex})
+	}
+	return pos
+}
+
+func assertPositions(t *testing.T, infos []TxInfo, want ...txPos) {
+	t.Helper()
+	got := positions(infos)
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestProcessBlockFilters(t *testing.T) {
+	backend := newTestBackend(t)
+
+	t.Run("contract", func(t *testing.T) {
+		infos := runScan(t, backend, scanFilter{statusFilter: 2})
+		assertPositions(t, infos, txPos{1, 0}, txPos{1, 2}, txPos{2, 0}, txPos{2, 1})
+	})
+	t.Run("calldata", func(t *testing.T) {
+		infos := runScan(t, backend, scanFilter{calldataPrefix: "a9059cbb", statusFilter: 2})
+		assertPositions(t, infos, txPos{1, 0}, txPos{2, 1})
+	})
+	t.Run("failed", func(t *testing.T) {
+		infos := runScan(t, backend, scanFilter{statusFilter: 0})
+		assertPositions(t, infos, txPos{1, 2})
+	})
+	t.Run("successful", func(t *testing.T) {
+		infos := runScan(t, backend, scanFilter{statusFilter: 1})
+		assertPositions(t, infos, txPos{1, 0}, txPos{2, 0}, txPos{2, 1})
+	})
+	t.Run("type", func(t *testing.T) {
+		infos := runScan(t, backend, scanFilter{statusFilter: 2, txTypes: []uint8{types.BlobTxType, types.LegacyTxType}})
+		assertPositions(t, infos, txPos{2, 0}, txPos{2, 1})
+	})
+}
+
+// 清空记录的失败，测试结束后恢复原值
+func saveFetchFailures(t *testing.T) {
+	failuresMu.Lock()
+	saved := fetchFailures
+	fetchFailures = nil
+	failuresMu.Unlock()
+	t.Cleanup(func() {
+		failuresMu.Lock()
+		fetchFailures = saved
+		failuresMu.Unlock()
+	})
+}
+
+func TestProcessBlockMissingBlock(t *testing.T) {
+	saveFetchFailures(t)
+	results := make(chan TxInfo, 1)
+	ProcessBlock(context.Background(), NewFakeBackend(), big.NewInt(5), testContract, "", 2, nil, nil, results)
+	close(results)
+	if len(results) != 0 {
+		t.Fatal("expected no results for a missing block")
+	}
+	if failures := FetchFailures(); len(failures) != 1 || failures[0].BlockNumber != 5 {
+		t.Errorf("failures = %+v, want block 5", failures)
+	}
+}
+
+func TestExtractFields(t *testing.T) {
+	infos := runScan(t, newTestBackend(t), scanFilter{statusFilter: 2})
+
+	transfer := infos[0]
+	if got := transfer.TxData["4byte"]; got != "0xa9059cbb" {
+		t.Errorf("4byte = %v, want 0xa9059cbb", got)
+	}
+	if _, err := exec.LookPath("cast"); err != nil {
+		if got := transfer.TxData["func"]; got != "transfer(address,uint256)" {
+			t.Errorf("func = %v, want transfer(address,uint256)", got)
+		}
+	}
+	if got := transfer.TxData["To"]; got != testContract.Hex() {
+		t.Errorf("To = %v, want %s", got, testContract.Hex())
+	}
+	if got := transfer.ReceiptData["GasUsed"]; got != uint64(51000) {
+		t.Errorf("GasUsed = %v, want 51000", got)
+	}
+	if got := transfer.ReceiptData["BlockNumber"]; got != "1" {
+		t.Errorf("BlockNumber = %v, want 1", got)
+	}
+	if got := transfer.TxData["BlobFeeCap"]; got != "" {
+		t.Errorf("BlobFeeCap = %v, want empty for a non-blob tx", got)
+	}
+	if got := transfer.ReceiptData["BlobGasPrice"]; got != "" {
+		t.Errorf("BlobGasPrice = %v, want empty for a non-blob tx", got)
+	}
+
+	failed := infos[1]
+	if got := failed.ReceiptData["Status"]; got != types.ReceiptStatusFailed {
+		t.Errorf("Status = %v, want %d", got, types.ReceiptStatusFailed)
+	}
+
+	blob := infos[2]
+	if got := blob.TxData["Type"]; got != uint8(types.BlobTxType) {
+		t.Errorf("Type = %v, want %d", got, types.BlobTxType)
+	}
+	if got := blob.TxData["BlobCount"]; got != 1 {
+		t.Errorf("BlobCount = %v, want 1", got)
+	}
+	if got := blob.TxData["BlobFeeCap"].(*big.Int); got.Int64() != 7 {
+		t.Errorf("BlobFeeCap = %v, want 7", got)
+	}
+	if got := blob.ReceiptData["BlobGasUsed"]; got != uint64(131072) {
+		t.Errorf("BlobGasUsed = %v, want 131072", got)
+	}
+	if got := blob.TxData["4byte"]; got != "0x" {
+		t.Errorf("4byte = %v, want 0x for empty calldata", got)
+	}
+}
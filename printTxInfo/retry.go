@@ -0,0 +1,151 @@
+package printTxInfo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"math/rand"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type RetryConfig struct {
+	Attempts  int           // 最多尝试次数（含第一次），小于 1 视为 1
+	BaseDelay time.Duration // 第一次重试前的等待时间，之后每次翻倍
+	MaxDelay  time.Duration // 单次等待时间上限
+}
+
+var DefaultRetryConfig = RetryConfig{Attempts: 5, BaseDelay: 500 * time.Millisecond, MaxDelay: 10 * time.Second}
+
+// 节点限流的 JSON-RPC 错误码（Infura、Alchemy 等使用）
+const rpcLimitExceeded = -32005
+
+// 判断错误是否值得重试：超时、限流、5xx 以及连接中断，其余错误（如交易不存在、参数错误）直接返回
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == 429 || httpErr.StatusCode >= 500
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == rpcLimitExceeded {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"rate limit", "too many requests", "timeout", "connection reset", "eof"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// 第 attempt 次重试前的等待时间：指数退避，在 [delay/2, delay] 内随机抖动，避免并发请求同时重试
+func backoff(cfg RetryConfig, attempt int) time.Duration {
+	delay := cfg.BaseDelay << attempt
+	if delay <= 0 || (cfg.MaxDelay > 0 && delay > cfg.MaxDelay) {
+		delay = cfg.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// 执行 fn，遇到可重试错误时按 cfg 退避重试，返回最后一次的错误
+func Retry(ctx context.Context, cfg RetryConfig, op string, fn func() error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = fn(); err == nil || !IsRetryable(err) || attempt+1 >= cfg.Attempts {
+			return err
+		}
+		delay := backoff(cfg, attempt)
+		log.Printf("%s failed (attempt %d/%d), retrying in %v: %v", op, attempt+1, cfg.Attempts, delay, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// 对所有请求按 RetryConfig 自动重试的 Backend
+type RetryBackend struct {
+	Backend
+	cfg RetryConfig
+}
+
+func NewRetryBackend(backend Backend, cfg RetryConfig) *RetryBackend {
+	return &RetryBackend{Backend: backend, cfg: cfg}
+}
+
+func (b *RetryBackend) BlockByNumber(ctx context.Context, number *big.Int) (block *types.Block, err error) {
+	err = Retry(ctx, b.cfg, "eth_getBlockByNumber "+number.String(), func() error {
+		block, err = b.Backend.BlockByNumber(ctx, number)
+		return err
+	})
+	return block, err
+}
+
+func (b *RetryBackend) HeaderByNumber(ctx context.Context, number *big.Int) (header *types.Header, err error) {
+	err = Retry(ctx, b.cfg, "eth_getHeaderByNumber "+number.String(), func() error {
+		header, err = b.Backend.HeaderByNumber(ctx, number)
+		return err
+	})
+	return header, err
+}
+
+func (b *RetryBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (receipt *types.Receipt, err error) {
+	err = Retry(ctx, b.cfg, "eth_getTransactionReceipt "+txHash.Hex(), func() error {
+		receipt, err = b.Backend.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return receipt, err
+}
+
+func (b *RetryBackend) TraceTransaction(ctx context.Context, txHash common.Hash, config map[string]interface{}) (result json.RawMessage, err error) {
+	err = Retry(ctx, b.cfg, "debug_traceTransaction "+txHash.Hex(), func() error {
+		result, err = b.Backend.TraceTransaction(ctx, txHash, config)
+		return err
+	})
+	return result, err
+}
+
+// 只重试建立连接和读取响应头，响应体开始写出后出错不再重试
+func (b *RetryBackend) StreamTraceTransaction(ctx context.Context, txHash common.Hash, config map[string]interface{}) (body io.ReadCloser, err error) {
+	err = Retry(ctx, b.cfg, "debug_traceTransaction "+txHash.Hex(), func() error {
+		body, err = streamTrace(ctx, b.Backend, txHash, config)
+		return err
+	})
+	return body, err
+}
+
+func (b *RetryBackend) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) (out []byte, err error) {
+	err = Retry(ctx, b.cfg, "eth_call", func() error {
+		out, err = callContract(ctx, b.Backend, msg, blockNumber)
+		return err
+	})
+	return out, err
+}
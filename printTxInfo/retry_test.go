@@ -0,0 +1,128 @@
+package printTxInfo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var testRetryConfig = RetryConfig{Attempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+// 前 failures 次请求返回 err 的 Backend
+type flakyBackend struct {
+	Backend
+	err error
+
+	mu       sync.Mutex
+	failures map[string]int
+}
+
+func newFlakyBackend(backend Backend, err error, failures map[string]int) *flakyBackend {
+	return &flakyBackend{Backend: backend, err: err, failures: failures}
+}
+
+func (b *flakyBackend) fail(key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures[key] > 0 {
+		b.failures[key]--
+		return true
+	}
+	return false
+}
+
+func (b *flakyBackend) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	if b.fail(fmt.Sprintf("block %d", number)) {
+		return nil, b.err
+	}
+	return b.Backend.BlockByNumber(ctx, number)
+}
+
+func (b *flakyBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if b.fail(txHash.Hex()) {
+		return nil, b.err
+	}
+	return b.Backend.TransactionReceipt(ctx, txHash)
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{rpc.HTTPError{StatusCode: 429, Status: "429 Too Many Requests"}, true},
+		{rpc.HTTPError{StatusCode: 503, Status: "503 Service Unavailable"}, true},
+		{rpc.HTTPError{StatusCode: 400, Status: "400 Bad Request"}, false},
+		{fmt.Errorf("get block: %w", context.DeadlineExceeded), true},
+		{errors.New("daily request count exceeded, request rate limited"), true},
+		{context.Canceled, false},
+		{errors.New("not found"), false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRetryBackendRecovers(t *testing.T) {
+	ResetFetchFailures()
+	backend := newTestBackend(t)
+	block, _ := backend.BlockByNumber(context.Background(), big.NewInt(2))
+
+	flaky := newFlakyBackend(backend, rpc.HTTPError{StatusCode: 429, Status: "429 Too Many Requests"}, map[string]int{
+		"block 1":                            2,
+		block.Transactions()[0].Hash().Hex(): 2,
+	})
+	infos := runScan(t, NewRetryBackend(flaky, testRetryConfig), scanFilter{statusFilter: 2})
+	assertPositions(t, infos, txPos{1, 0}, txPos{1, 2}, txPos{2, 0}, txPos{2, 1})
+	if failures := FetchFailures(); len(failures) != 0 {
+		t.Fatalf("unexpected failures: %v", failures)
+	}
+}
+
+func TestRetryBackendRecordsFailures(t *testing.T) {
+	ResetFetchFailures()
+	defer ResetFetchFailures()
+	backend := newTestBackend(t)
+	block, _ := backend.BlockByNumber(context.Background(), big.NewInt(2))
+	blobTx := block.Transactions()[0].Hash()
+
+	// 重试次数用尽的限流错误
+	flaky := newFlakyBackend(backend, rpc.HTTPError{StatusCode: 429, Status: "429 Too Many Requests"}, map[string]int{
+		"block 1":    3,
+		blobTx.Hex(): 3,
+	})
+	infos := runScan(t, NewRetryBackend(flaky, testRetryConfig), scanFilter{statusFilter: 2})
+	assertPositions(t, infos, txPos{2, 1})
+
+	failures := FetchFailures()
+	if len(failures) != 2 {
+		t.Fatalf("got %d failures, want 2: %v", len(failures), failures)
+	}
+	if failures[0].BlockNumber != 1 || failures[0].TxHash != (common.Hash{}) {
+		t.Errorf("unexpected block failure: %+v", failures[0])
+	}
+	if failures[1].BlockNumber != 2 || failures[1].TxHash != blobTx {
+		t.Errorf("unexpected receipt failure: %+v", failures[1])
+	}
+}
+
+func TestRetryStopsOnPermanentError(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), testRetryConfig, "test", func() error {
+		calls++
+		return errors.New("invalid argument")
+	})
+	if err == nil || calls != 1 {
+		t.Fatalf("got %d calls, err %v; want 1 call and an error", calls, err)
+	}
+}
@@ -3,6 +3,8 @@ package printTxInfo
 import (
 	"encoding/json"
 	"io/ioutil"
+
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
 type Signature struct {
@@ -19,6 +21,8 @@ type TxInfo struct {
 	TransactionIndex uint
 	TxData           map[string]interface{}
 	ReceiptData      map[string]interface{}
+	Tx               *types.Transaction
+	Receipt          *types.Receipt
 }
 
 var signaturesMap map[string]Signature
@@ -0,0 +1,269 @@
+package printTxInfo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// prestateTracer diffMode 输出中的账户状态，未变化的字段被省略
+type prestateAccount struct {
+	Balance *hexutil.Big                `json:"balance"`
+	Nonce   *uint64                     `json:"nonce"`
+	Code    hexutil.Bytes               `json:"code"`
+	Storage map[common.Hash]common.Hash `json:"storage"`
+}
+
+type SlotDiff struct {
+	Slot   common.Hash
+	Before common.Hash
+	After  common.Hash
+	Labels []string // 根据存储布局解码出的变量，例如 owner=0x..；未提供布局或无法识别时为空
+}
+
+// 一个账户在交易中的状态变化，未变化的字段为 nil
+type AccountDiff struct {
+	Address       common.Address
+	BalanceBefore *big.Int
+	BalanceAfter  *big.Int
+	NonceBefore   *uint64
+	NonceAfter    *uint64
+	CodeChanged   bool
+	Deleted       bool
+	Storage       []SlotDiff
+}
+
+type StateDiff []AccountDiff
+
+func (d StateDiff) String() string {
+	items := make([]string, len(d))
+	for i, a := range d {
+		items[i] = a.String()
+	}
+	return "[" + strings.Join(items, "; ") + "]"
+}
+
+func (a AccountDiff) String() string {
+	var parts []string
+	if a.Deleted {
+		parts = append(parts, "deleted")
+	}
+	if a.BalanceAfter != nil {
+		delta := new(big.Int).Sub(a.BalanceAfter, a.BalanceBefore)
+		sign := ""
+		if delta.Sign() >= 0 {
+			sign = "+"
+		}
+		parts = append(parts, fmt.Sprintf("balance %s -> %s (%s%s)", a.BalanceBefore, a.BalanceAfter, sign, delta))
+	}
+	if a.NonceAfter != nil {
+		parts = append(parts, fmt.Sprintf("nonce %d -> %d", *a.NonceBefore, *a.NonceAfter))
+	}
+	if a.CodeChanged {
+		parts = append(parts, "code changed")
+	}
+	for _, s := range a.Storage {
+		slot := s.Slot.Hex()
+		if len(s.Labels) > 0 {
+			slot += "(" + strings.Join(s.Labels, ",") + ")"
+		}
+		parts = append(parts, fmt.Sprintf("slot %s %s -> %s", slot, s.Before.Hex(), s.After.Hex()))
+	}
+	return a.Address.Hex() + ": " + strings.Join(parts, ", ")
+}
+
+// 使用 prestateTracer 的 diffMode 获取交易引起的余额、nonce、代码和存储变化，按地址排序
+func TraceStateDiff(ctx context.Context, backend Backend, txHash common.Hash) (StateDiff, error) {
+	raw, err := backend.TraceTransaction(ctx, txHash, map[string]interface{}{
+		"tracer":       "prestateTracer",
+		"tracerConfig": map[string]interface{}{"diffMode": true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to trace tx %s: %w", txHash.Hex(), err)
+	}
+	var result struct {
+		Pre  map[common.Address]*prestateAccount `json:"pre"`
+		Post map[common.Address]*prestateAccount `json:"post"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("invalid prestateTracer result: %w", err)
+	}
+	return buildStateDiff(result.Pre, result.Post), nil
+}
+
+func buildStateDiff(pre, post map[common.Address]*prestateAccount) StateDiff {
+	addrs := make(map[common.Address]bool)
+	for addr := range pre {
+		addrs[addr] = true
+	}
+	for addr := range post {
+		addrs[addr] = true
+	}
+
+	var diff StateDiff
+	for addr := range addrs {
+		before, after := pre[addr], post[addr]
+		if before == nil {
+			before = &prestateAccount{}
+		}
+		a := AccountDiff{Address: addr}
+		// 只出现在 pre 中的账户在交易中被删除
+		if after == nil {
+			a.Deleted = true
+			after = &prestateAccount{Balance: new(hexutil.Big), Nonce: new(uint64), Storage: map[common.Hash]common.Hash{}}
+			for slot := range before.Storage {
+				after.Storage[slot] = common.Hash{}
+			}
+		}
+
+		if after.Balance != nil {
+			a.BalanceBefore, a.BalanceAfter = new(big.Int), after.Balance.ToInt()
+			if before.Balance != nil {
+				a.BalanceBefore = before.Balance.ToInt()
+			}
+		}
+		if after.Nonce != nil {
+			a.NonceBefore, a.NonceAfter = new(uint64), after.Nonce
+			if before.Nonce != nil {
+				a.NonceBefore = before.Nonce
+			}
+		}
+		a.CodeChanged = len(after.Code) > 0 && string(after.Code) != string(before.Code)
+
+		// post 中省略了被清零的存储槽
+		slots := make(map[common.Hash]bool)
+		for slot := range before.Storage {
+			slots[slot] = true
+		}
+		for slot := range after.Storage {
+			slots[slot] = true
+		}
+		for slot := range slots {
+			if before.Storage[slot] != after.Storage[slot] {
+				a.Storage = append(a.Storage, SlotDiff{Slot: slot, Before: before.Storage[slot], After: after.Storage[slot]})
+			}
+		}
+		sort.Slice(a.Storage, func(i, j int) bool { return a.Storage[i].Slot.Cmp(a.Storage[j].Slot) < 0 })
+		diff = append(diff, a)
+	}
+	sort.Slice(diff, func(i, j int) bool { return diff[i].Address.Cmp(diff[j].Address) < 0 })
+
+	if storageLayouts != nil {
+		labelStorage(diff)
+	}
+	return diff
+}
+
+// solc 输出的 storageLayout
+type StorageLayout struct {
+	Storage []struct {
+		Label  string `json:"label"`
+		Slot   string `json:"slot"`
+		Offset int    `json:"offset"`
+		Type   string `json:"type"`
+	} `json:"storage"`
+	Types map[string]struct {
+		Encoding      string `json:"encoding"`
+		Label         string `json:"label"`
+		NumberOfBytes string `json:"numberOfBytes"`
+		Key           string `json:"key"`
+		Value         string `json:"value"`
+	} `json:"types"`
+}
+
+var storageLayouts map[common.Address]*StorageLayout
+
+// 加载合约的存储布局（solc --storage-layout 的输出），用于解码 stateDiff 中该合约的存储槽
+func LoadStorageLayout(contract common.Address, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var layout StorageLayout
+	if err := json.Unmarshal(data, &layout); err != nil {
+		return fmt.Errorf("invalid storage layout: %w", err)
+	}
+	if storageLayouts == nil {
+		storageLayouts = make(map[common.Address]*StorageLayout)
+	}
+	storageLayouts[contract] = &layout
+	return nil
+}
+
+// 按存储布局标注存储槽：直接存放的变量按 slot 和 offset 解码；
+// 以 address 为键的 mapping 尝试用本次变化中出现的地址计算槽位
+func labelStorage(diff StateDiff) {
+	for i := range diff {
+		layout, ok := storageLayouts[diff[i].Address]
+		if !ok {
+			continue
+		}
+		for j := range diff[i].Storage {
+			s := &diff[i].Storage[j]
+			for _, v := range layout.Storage {
+				base, ok := new(big.Int).SetString(v.Slot, 10)
+				if !ok {
+					continue
+				}
+				t := layout.Types[v.Type]
+				switch t.Encoding {
+				case "inplace":
+					if common.BigToHash(base) != s.Slot {
+						continue
+					}
+					size, _ := strconv.Atoi(t.NumberOfBytes)
+					before := decodeSlotValue(s.Before, v.Offset, size, t.Label)
+					after := decodeSlotValue(s.After, v.Offset, size, t.Label)
+					if before != after {
+						s.Labels = append(s.Labels, fmt.Sprintf("%s: %s -> %s", v.Label, before, after))
+					}
+				case "mapping":
+					if layout.Types[t.Key].Label != "address" {
+						continue
+					}
+					for _, key := range diff {
+						slot := crypto.Keccak256Hash(common.LeftPadBytes(key.Address.Bytes(), 32), common.BigToHash(base).Bytes())
+						if slot == s.Slot {
+							valueType := layout.Types[t.Value]
+							size, _ := strconv.Atoi(valueType.NumberOfBytes)
+							s.Labels = append(s.Labels, fmt.Sprintf("%s[%s]: %s -> %s", v.Label, key.Address.Hex(),
+								decodeSlotValue(s.Before, 0, size, valueType.Label), decodeSlotValue(s.After, 0, size, valueType.Label)))
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// 从槽中按 offset（从低位开始的字节数）取出 size 字节并按类型格式化
+func decodeSlotValue(word common.Hash, offset, size int, typeLabel string) string {
+	if size <= 0 || offset+size > 32 {
+		return word.Hex()
+	}
+	raw := word[32-offset-size : 32-offset]
+	switch {
+	case typeLabel == "address" || strings.HasPrefix(typeLabel, "contract "):
+		return common.BytesToAddress(raw).Hex()
+	case typeLabel == "bool":
+		return strconv.FormatBool(raw[len(raw)-1] != 0)
+	case strings.HasPrefix(typeLabel, "uint"):
+		return new(big.Int).SetBytes(raw).String()
+	case strings.HasPrefix(typeLabel, "int"):
+		v := new(big.Int).SetBytes(raw)
+		if raw[0]&0x80 != 0 {
+			v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(size*8)))
+		}
+		return v.String()
+	}
+	return hexutil.Encode(raw)
+}
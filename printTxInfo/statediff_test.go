@@ -0,0 +1,95 @@
+package printTxInfo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const testLayout = `{
+  "storage": [
+    {"label": "owner", "slot": "0", "offset": 0, "type": "t_address"},
+    {"label": "paused", "slot": "0", "offset": 20, "type": "t_bool"},
+    {"label": "balances", "slot": "1", "offset": 0, "type": "t_mapping(t_address,t_uint256)"},
+    {"label": "total", "slot": "2", "offset": 0, "type": "t_uint256"}
+  ],
+  "types": {
+    "t_address": {"encoding": "inplace", "label": "address", "numberOfBytes": "20"},
+    "t_bool": {"encoding": "inplace", "label": "bool", "numberOfBytes": "1"},
+    "t_uint256": {"encoding": "inplace", "label": "uint256", "numberOfBytes": "32"},
+    "t_mapping(t_address,t_uint256)": {"encoding": "mapping", "label": "mapping(address => uint256)", "numberOfBytes": "32", "key": "t_address", "value": "t_uint256"}
+  }
+}`
+
+func TestTraceStateDiff(t *testing.T) {
+	defer func() { storageLayouts = nil }()
+
+	sender := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	destroyed := common.HexToAddress("0x0000000000000000000000000000000000000003")
+	balanceSlot := crypto.Keccak256Hash(common.LeftPadBytes(sender.Bytes(), 32), common.BigToHash(common.Big1).Bytes())
+	owner := "000000000000000000000000000000000000000000000000000000000000beef"
+
+	trace := fmt.Sprintf(`{
+	  "pre": {
+	    "%[1]s": {"balance": "0x100", "nonce": 4},
+	    "%[2]s": {"balance": "0x0", "nonce": 1, "storage": {
+	      "0x0000000000000000000000000000000000000000000000000000000000000000": "0x%[4]s",
+	      "%[5]s": "0x0000000000000000000000000000000000000000000000000000000000000064",
+	      "0x0000000000000000000000000000000000000000000000000000000000000002": "0x0000000000000000000000000000000000000000000000000000000000000007"
+	    }},
+	    "%[3]s": {"balance": "0x5", "code": "0x00"}
+	  },
+	  "post": {
+	    "%[1]s": {"balance": "0xf0", "nonce": 5},
+	    "%[2]s": {"storage": {
+	      "0x0000000000000000000000000000000000000000000000000000000000000000": "0x0000000000000000000000010000000000000000000000000000000000%[6]s",
+	      "%[5]s": "0x0000000000000000000000000000000000000000000000000000000000000032"
+	    }}
+	  }
+	}`, sender.Hex(), testContract.Hex(), destroyed.Hex(), owner, balanceSlot.Hex(), owner[58:])
+
+	path := filepath.Join(t.TempDir(), "layout.json")
+	os.WriteFile(path, []byte(testLayout), 0644)
+	if err := LoadStorageLayout(testContract, path); err != nil {
+		t.Fatal(err)
+	}
+
+	backend := NewFakeBackend()
+	txHash := common.HexToHash("0x01")
+	backend.SetTrace(txHash, json.RawMessage(trace))
+	diff, err := TraceStateDiff(context.Background(), backend, txHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff) != 3 {
+		t.Fatalf("got %d accounts, want 3: %v", len(diff), diff)
+	}
+
+	if got, want := diff[0].String(), sender.Hex()+": balance 256 -> 240 (-16), nonce 4 -> 5"; got != want {
+		t.Errorf("sender diff:\n got %s\nwant %s", got, want)
+	}
+	if got, want := diff[1].String(), destroyed.Hex()+": deleted, balance 5 -> 0 (-5), nonce 0 -> 0"; got != want {
+		t.Errorf("deleted diff:\n got %s\nwant %s", got, want)
+	}
+
+	contract := diff[2]
+	if len(contract.Storage) != 3 {
+		t.Fatalf("got %d storage changes, want 3: %v", len(contract.Storage), contract)
+	}
+	wantLabels := map[common.Hash][]string{
+		common.BigToHash(common.Big0): {"paused: false -> true"},
+		common.BigToHash(common.Big2): {"total: 7 -> 0"},
+		balanceSlot:                   {fmt.Sprintf("balances[%s]: 100 -> 50", sender.Hex())},
+	}
+	for _, s := range contract.Storage {
+		if fmt.Sprint(s.Labels) != fmt.Sprint(wantLabels[s.Slot]) {
+			t.Errorf("slot %s labels = %v, want %v", s.Slot.Hex(), s.Labels, wantLabels[s.Slot])
+		}
+	}
+}
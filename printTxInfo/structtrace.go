@@ -0,0 +1,192 @@
+package printTxInfo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type TraceConfig struct {
+	DisableMemory  bool
+	DisableStack   bool
+	DisableStorage bool
+	MaxDepth       int // 只输出调用深度不超过 MaxDepth 的操作码，0 表示不限制；在本地过滤，不减小节点响应
+}
+
+// 交易执行结果的汇总，作为输出的最后一行
+type traceSummary struct {
+	Tx          common.Hash `json:"tx"`
+	Gas         uint64      `json:"gas"`
+	Failed      bool        `json:"failed"`
+	ReturnValue string      `json:"returnValue"`
+	Steps       int         `json:"steps"`
+}
+
+// 使用 struct logger 获取交易的操作码级 trace，按 JSON Lines 写入 w：
+// 每个操作码（pc、op、gas、depth、stack、memory、storage）一行，最后一行为执行结果汇总，返回写入的操作码数量。
+// Backend 支持 TraceStreamer 时边读取节点响应边写出，否则先完整读取响应；
+// DisableMemory、DisableStack、DisableStorage 由节点处理，可减小响应本身，MaxDepth 在本地过滤，只减小输出
+func WriteStructTrace(ctx context.Context, backend Backend, txHash common.Hash, cfg TraceConfig, w io.Writer) (int, error) {
+	config := map[string]interface{}{
+		"enableMemory":   !cfg.DisableMemory,
+		"disableStack":   cfg.DisableStack,
+		"disableStorage": cfg.DisableStorage,
+	}
+	body, err := streamTrace(ctx, backend, txHash, config)
+	if err == nil {
+		defer body.Close()
+		var steps int
+		err = decodeRPCResult(json.NewDecoder(body), func(dec *json.Decoder) (err error) {
+			steps, err = writeStructLogs(dec, txHash, cfg.MaxDepth, w)
+			return err
+		})
+		if err != nil {
+			return steps, fmt.Errorf("failed to trace tx %s: %w", txHash.Hex(), err)
+		}
+		return steps, nil
+	}
+	if !errors.Is(err, errNoTraceStreamer) {
+		return 0, fmt.Errorf("failed to trace tx %s: %w", txHash.Hex(), err)
+	}
+
+	raw, err := backend.TraceTransaction(ctx, txHash, config)
+	if err != nil {
+		return 0, fmt.Errorf("failed to trace tx %s: %w", txHash.Hex(), err)
+	}
+	return writeStructLogs(json.NewDecoder(bytes.NewReader(raw)), txHash, cfg.MaxDepth, w)
+}
+
+// JSON-RPC 响应中的错误对象，实现 rpc.Error
+type jsonRPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *jsonRPCError) Error() string  { return e.Message }
+func (e *jsonRPCError) ErrorCode() int { return e.Code }
+
+// 逐个读取 JSON-RPC 响应的字段，读到 result 时交给 decodeResult 从当前位置继续解码
+func decodeRPCResult(dec *json.Decoder, decodeResult func(dec *json.Decoder) error) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	found := false
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("invalid JSON-RPC response: %w", err)
+		}
+		switch key {
+		case "result":
+			found = true
+			err = decodeResult(dec)
+		case "error":
+			rpcErr := new(jsonRPCError)
+			if err = dec.Decode(rpcErr); err == nil {
+				err = rpcErr
+			}
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if !found {
+		return errors.New("invalid JSON-RPC response: missing result")
+	}
+	return nil
+}
+
+// 逐个解码 structLogs 中的操作码并立即写出，避免再把整个操作码列表解码成结构体
+func writeStructLogs(dec *json.Decoder, txHash common.Hash, maxDepth int, w io.Writer) (int, error) {
+	summary := traceSummary{Tx: txHash}
+	if err := expectDelim(dec, '{'); err != nil {
+		return 0, err
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return summary.Steps, fmt.Errorf("invalid struct logger result: %w", err)
+		}
+		switch key {
+		case "gas":
+			err = dec.Decode(&summary.Gas)
+		case "failed":
+			err = dec.Decode(&summary.Failed)
+		case "returnValue":
+			err = dec.Decode(&summary.ReturnValue)
+		case "structLogs":
+			err = writeSteps(dec, maxDepth, w, &summary.Steps)
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return summary.Steps, err
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return summary.Steps, err
+	}
+
+	line, err := json.Marshal(summary)
+	if err != nil {
+		return summary.Steps, err
+	}
+	if _, err := fmt.Fprintf(w, "%s\n", line); err != nil {
+		return summary.Steps, err
+	}
+	return summary.Steps, nil
+}
+
+func writeSteps(dec *json.Decoder, maxDepth int, w io.Writer, steps *int) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for dec.More() {
+		var step json.RawMessage
+		if err := dec.Decode(&step); err != nil {
+			return fmt.Errorf("invalid struct log: %w", err)
+		}
+		if maxDepth > 0 {
+			var header struct {
+				Depth int `json:"depth"`
+			}
+			if err := json.Unmarshal(step, &header); err != nil {
+				return fmt.Errorf("invalid struct log: %w", err)
+			}
+			if header.Depth > maxDepth {
+				continue
+			}
+		}
+		buf.Reset()
+		if err := json.Compact(&buf, step); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+		*steps++
+	}
+	return expectDelim(dec, ']')
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("invalid struct logger result: %w", err)
+	}
+	if tok != delim {
+		return fmt.Errorf("invalid struct logger result: expected %v, got %v", delim, tok)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package printTxInfo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestWriteStructTrace(t *testing.T) {
+	trace := `{
+	  "gas": 53000,
+	  "failed": true,
+	  "returnValue": "08c379a0",
+	  "structLogs": [
+	    {"pc": 0, "op": "PUSH1", "gas": 30000, "gasCost": 3, "depth": 1, "stack": []},
+	    {"pc": 2, "op": "CALL", "gas": 29997, "gasCost": 100, "depth": 1, "stack": ["0x1", "0x2"],
+	     "memory": ["0000000000000000000000000000000000000000000000000000000000000000"]},
+	    {"pc": 0, "op": "SSTORE", "gas": 20000, "gasCost": 2900, "depth": 2, "stack": ["0x0", "0x1"],
+	     "storage": {"0000000000000000000000000000000000000000000000000000000000000000": "0000000000000000000000000000000000000000000000000000000000000001"}},
+	    {"pc": 3, "op": "REVERT", "gas": 9000, "gasCost": 0, "depth": 1, "stack": ["0x0", "0x0"]}
+	  ]
+	}`
+	backend := NewFakeBackend()
+	txHash := common.HexToHash("0x01")
+	backend.SetTrace(txHash, json.RawMessage(trace))
+
+	cases := []struct {
+		name     string
+		maxDepth int
+		wantOps  []string
+	}{
+		{"all", 0, []string{"PUSH1", "CALL", "SSTORE", "REVERT"}},
+		{"depth 1", 1, []string{"PUSH1", "CALL", "REVERT"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			n, err := WriteStructTrace(context.Background(), backend, txHash, TraceConfig{MaxDepth: c.maxDepth}, &out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != len(c.wantOps) {
+				t.Errorf("wrote %d steps, want %d", n, len(c.wantOps))
+			}
+
+			lines := bytes.Split(bytes.TrimSuffix(out.Bytes(), []byte("\n")), []byte("\n"))
+			if len(lines) != len(c.wantOps)+1 {
+				t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(c.wantOps)+1, out.String())
+			}
+			for i, op := range c.wantOps {
+				var step struct {
+					Op string `json:"op"`
+				}
+				if err := json.Unmarshal(lines[i], &step); err != nil {
+					t.Fatalf("line %d: %v", i, err)
+				}
+				if step.Op != op {
+					t.Errorf("line %d op = %s, want %s", i, step.Op, op)
+				}
+			}
+
+			var summary traceSummary
+			if err := json.Unmarshal(lines[len(lines)-1], &summary); err != nil {
+				t.Fatal(err)
+			}
+			want := traceSummary{Tx: txHash, Gas: 53000, Failed: true, ReturnValue: "08c379a0", Steps: len(c.wantOps)}
+			if summary != want {
+				t.Errorf("summary = %+v, want %+v", summary, want)
+			}
+		})
+	}
+}
+
+// 收到第一行输出时关闭 first
+type signalWriter struct {
+	bytes.Buffer
+	first chan struct{}
+}
+
+func (w *signalWriter) Write(p []byte) (int, error) {
+	if w.Len() == 0 {
+		close(w.first)
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestWriteStructTraceStreams(t *testing.T) {
+	out := &signalWriter{first: make(chan struct{})}
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Method != "debug_traceTransaction" {
+			t.Errorf("unexpected request %q: %v", req.Method, err)
+		}
+		io.WriteString(rw, `{"jsonrpc":"2.0","id":1,"result":{"gas":21000,"failed":false,"returnValue":"","structLogs":[`+
+			`{"pc":0,"op":"PUSH1","gas":30000,"gasCost":3,"depth":1},`)
+		rw.(http.Flusher).Flush()
+		// 第一个操作码写出后才发送剩余部分，确认响应是边读取边写出的
+		<-out.first
+		io.WriteString(rw, `{"pc":2,"op":"STOP","gas":29997,"gasCost":0,"depth":1}]}}`)
+	}))
+	defer srv.Close()
+
+	pool, err := NewPoolBackend([]*Endpoint{NewEndpoint(srv.URL, &RPCBackend{URL: srv.URL}, 0)}, RouteRoundRobin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := NewRetryBackend(pool, testRetryConfig)
+	txHash := common.HexToHash("0x01")
+	n, err := WriteStructTrace(context.Background(), backend, txHash, TraceConfig{}, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf(`{"pc":0,"op":"PUSH1","gas":30000,"gasCost":3,"depth":1}
+{"pc":2,"op":"STOP","gas":29997,"gasCost":0,"depth":1}
+{"tx":"%s","gas":21000,"failed":false,"returnValue":"","steps":2}
+`, txHash.Hex())
+	if n != 2 || out.String() != want {
+		t.Errorf("wrote %d steps:\n%s\nwant:\n%s", n, out.String(), want)
+	}
+}
+
+func TestWriteStructTraceRPCError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		io.WriteString(rw, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"transaction not found"}}`)
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	_, err := WriteStructTrace(context.Background(), &RPCBackend{URL: srv.URL}, common.HexToHash("0x01"), TraceConfig{}, &out)
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != -32000 {
+		t.Fatalf("err = %v, want JSON-RPC error -32000", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+}
@@ -0,0 +1,214 @@
+package printTxInfo
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"golang.org/x/time/rate"
+)
+
+// 自适应并发：每 window 次请求评估一次，错误率或平均延迟超过阈值时并发减半，否则加一（AIMD）
+type AdaptiveConcurrency struct {
+	min, max      int
+	targetLatency time.Duration
+	maxErrorRate  float64
+	window        int
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	limit   int
+	active  int
+	samples int
+	errors  int
+	total   time.Duration
+}
+
+func NewAdaptiveConcurrency(min, max int, targetLatency time.Duration) *AdaptiveConcurrency {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	a := &AdaptiveConcurrency{
+		min:           min,
+		max:           max,
+		targetLatency: targetLatency,
+		maxErrorRate:  0.1,
+		window:        20,
+		limit:         min,
+	}
+	a.cond = sync.NewCond(&a.mu)
+	return a
+}
+
+// 获取一个并发名额，超过当前上限时阻塞
+func (a *AdaptiveConcurrency) Acquire() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for a.active >= a.limit {
+		a.cond.Wait()
+	}
+	a.active++
+}
+
+func (a *AdaptiveConcurrency) Release() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.active--
+	a.cond.Broadcast()
+}
+
+func (a *AdaptiveConcurrency) Limit() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.limit
+}
+
+// 记录一次请求的耗时和结果
+func (a *AdaptiveConcurrency) Observe(latency time.Duration, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.samples++
+	a.total += latency
+	if err != nil && IsRetryable(err) {
+		a.errors++
+	}
+	if a.samples < a.window {
+		return
+	}
+
+	avg := a.total / time.Duration(a.samples)
+	errorRate := float64(a.errors) / float64(a.samples)
+	old := a.limit
+	if errorRate > a.maxErrorRate || (a.targetLatency > 0 && avg > a.targetLatency) {
+		a.limit = max(a.min, a.limit/2)
+	} else {
+		a.limit = min(a.max, a.limit+1)
+	}
+	if a.limit != old {
+		log.Printf("Adjusting concurrency %d -> %d (avg latency %v, error rate %.0f%%)", old, a.limit, avg, errorRate*100)
+		a.cond.Broadcast()
+	}
+	a.samples, a.errors, a.total = 0, 0, 0
+}
+
+// 对请求限速，并把每次请求的耗时和结果反馈给自适应并发控制
+type ThrottledBackend struct {
+	Backend
+	limiter  *rate.Limiter
+	adaptive *AdaptiveConcurrency
+}
+
+// rps 为 0 表示不限速，adaptive 为 nil 表示不做自适应并发控制
+func NewThrottledBackend(backend Backend, rps float64, adaptive *AdaptiveConcurrency) *ThrottledBackend {
+	b := &ThrottledBackend{Backend: backend, adaptive: adaptive}
+	if rps > 0 {
+		b.limiter = rate.NewLimiter(rate.Limit(rps), max(1, int(rps)))
+	}
+	return b
+}
+
+func (b *ThrottledBackend) do(ctx context.Context, fn func() error) error {
+	if b.limiter != nil {
+		if err := b.limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	start := time.Now()
+	err := fn()
+	if b.adaptive != nil {
+		b.adaptive.Observe(time.Since(start), err)
+	}
+	return err
+}
+
+func (b *ThrottledBackend) BlockByNumber(ctx context.Context, number *big.Int) (block *types.Block, err error) {
+	err = b.do(ctx, func() error {
+		block, err = b.Backend.BlockByNumber(ctx, number)
+		return err
+	})
+	return block, err
+}
+
+func (b *ThrottledBackend) HeaderByNumber(ctx context.Context, number *big.Int) (header *types.Header, err error) {
+	err = b.do(ctx, func() error {
+		header, err = b.Backend.HeaderByNumber(ctx, number)
+		return err
+	})
+	return header, err
+}
+
+func (b *ThrottledBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (receipt *types.Receipt, err error) {
+	err = b.do(ctx, func() error {
+		receipt, err = b.Backend.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return receipt, err
+}
+
+func (b *ThrottledBackend) TraceTransaction(ctx context.Context, txHash common.Hash, config map[string]interface{}) (result json.RawMessage, err error) {
+	err = b.do(ctx, func() error {
+		result, err = b.Backend.TraceTransaction(ctx, txHash, config)
+		return err
+	})
+	return result, err
+}
+
+func (b *ThrottledBackend) StreamTraceTransaction(ctx context.Context, txHash common.Hash, config map[string]interface{}) (body io.ReadCloser, err error) {
+	err = b.do(ctx, func() error {
+		body, err = streamTrace(ctx, b.Backend, txHash, config)
+		return err
+	})
+	return body, err
+}
+
+func (b *ThrottledBackend) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) (out []byte, err error) {
+	err = b.do(ctx, func() error {
+		out, err = callContract(ctx, b.Backend, msg, blockNumber)
+		return err
+	})
+	return out, err
+}
+
+// 用 workers 个 goroutine 依次处理 [start, end] 内的区块；adaptive 不为 nil 时同时处理的区块数受其限制
+func RunBlocks(ctx context.Context, start, end uint64, workers int, adaptive *AdaptiveConcurrency, process func(number uint64)) {
+	if workers < 1 {
+		workers = 1
+	}
+	blocks := make(chan uint64)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for number := range blocks {
+				if adaptive != nil {
+					adaptive.Acquire()
+				}
+				process(number)
+				if adaptive != nil {
+					adaptive.Release()
+				}
+			}
+		}()
+	}
+
+	for number := start; number <= end && ctx.Err() == nil; number++ {
+		blocks <- number
+		if number == end {
+			break
+		}
+	}
+	close(blocks)
+	wg.Wait()
+}
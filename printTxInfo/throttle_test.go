@@ -0,0 +1,78 @@
+package printTxInfo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestAdaptiveConcurrency(t *testing.T) {
+	a := NewAdaptiveConcurrency(1, 4, 100*time.Millisecond)
+	observe := func(latency time.Duration, err error) {
+		for i := 0; i < a.window; i++ {
+			a.Observe(latency, err)
+		}
+	}
+
+	// 节点跟得上时逐步增加，不超过上限
+	for i := 0; i < 5; i++ {
+		observe(10*time.Millisecond, nil)
+	}
+	if got := a.Limit(); got != 4 {
+		t.Fatalf("limit = %d after fast responses, want 4", got)
+	}
+
+	observe(500*time.Millisecond, nil)
+	if got := a.Limit(); got != 2 {
+		t.Fatalf("limit = %d after slow responses, want 2", got)
+	}
+
+	observe(10*time.Millisecond, rpc.HTTPError{StatusCode: 429})
+	if got := a.Limit(); got != 1 {
+		t.Fatalf("limit = %d after rate limit errors, want 1", got)
+	}
+
+	// 永久错误不代表节点过载
+	observe(10*time.Millisecond, errors.New("not found"))
+	if got := a.Limit(); got != 2 {
+		t.Fatalf("limit = %d after permanent errors, want 2", got)
+	}
+}
+
+func TestRunBlocks(t *testing.T) {
+	a := NewAdaptiveConcurrency(2, 2, 0)
+
+	var (
+		mu      sync.Mutex
+		seen    = make(map[uint64]bool)
+		running int32
+		peak    int32
+	)
+	RunBlocks(context.Background(), 10, 29, 8, a, func(number uint64) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+
+		mu.Lock()
+		seen[number] = true
+		mu.Unlock()
+	})
+
+	if len(seen) != 20 || !seen[10] || !seen[29] {
+		t.Fatalf("processed %d blocks, want 10-29", len(seen))
+	}
+	if peak > 2 {
+		t.Fatalf("%d blocks processed concurrently, want at most 2", peak)
+	}
+}
@@ -0,0 +1,128 @@
+package printTxInfo
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// 解析时间参数：unix 时间戳、日期（如 2024-05-01、2024-05-01 12:00，按 UTC）、
+// RFC3339 时间，或相对 now 的时长（如 24h、90m、7d、2w）
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if ts, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(ts, 0).UTC(), nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	if d, err := parseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, expected a unix timestamp, a date or a duration like 24h or 7d", s)
+}
+
+// 在 time.ParseDuration 的基础上支持 d（天）和 w（周）
+func parseDuration(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.ParseFloat(n, 64)
+			if err != nil || v < 0 {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			return time.Duration(v * float64(unit)), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
+
+// 通过二分查找区块头把时间转换为区块号，查询过的区块时间会被缓存
+type BlockTimeResolver struct {
+	backend Backend
+
+	mu    sync.Mutex
+	times map[uint64]uint64
+}
+
+func NewBlockTimeResolver(backend Backend) *BlockTimeResolver {
+	return &BlockTimeResolver{backend: backend, times: make(map[uint64]uint64)}
+}
+
+func (r *BlockTimeResolver) blockTime(ctx context.Context, number uint64) (uint64, error) {
+	r.mu.Lock()
+	t, ok := r.times[number]
+	r.mu.Unlock()
+	if ok {
+		return t, nil
+	}
+
+	header, err := r.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get header %d: %w", number, err)
+	}
+	r.mu.Lock()
+	r.times[number] = header.Time
+	r.mu.Unlock()
+	return header.Time, nil
+}
+
+// 在 [0, latest] 中查找第一个时间戳不早于 t 的区块，所有区块都早于 t 时返回 latest+1
+func (r *BlockTimeResolver) firstAtOrAfter(ctx context.Context, t time.Time, latest uint64) (uint64, error) {
+	target := uint64(max(t.Unix(), 0))
+	lo, hi := uint64(0), latest+1
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		blockTime, err := r.blockTime(ctx, mid)
+		if err != nil {
+			return 0, err
+		}
+		if blockTime >= target {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo, nil
+}
+
+// 返回时间戳不早于 since 的第一个区块
+func (r *BlockTimeResolver) BlockSince(ctx context.Context, since time.Time, latest uint64) (uint64, error) {
+	number, err := r.firstAtOrAfter(ctx, since, latest)
+	if err != nil {
+		return 0, err
+	}
+	if number > latest {
+		return 0, fmt.Errorf("no block at or after %s, latest block is %d", since.Format(time.RFC3339), latest)
+	}
+	return number, nil
+}
+
+// 返回时间戳不晚于 until 的最后一个区块
+func (r *BlockTimeResolver) BlockUntil(ctx context.Context, until time.Time, latest uint64) (uint64, error) {
+	number, err := r.firstAtOrAfter(ctx, until.Add(time.Second), latest)
+	if err != nil {
+		return 0, err
+	}
+	if number == 0 {
+		return 0, fmt.Errorf("no block at or before %s", until.Format(time.RFC3339))
+	}
+	return number - 1, nil
+}
@@ -0,0 +1,97 @@
+package printTxInfo
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2024, 5, 8, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"1714521600", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{"2024-05-01", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{"2024-05-01 08:30", time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)},
+		{"2024-05-01T08:30:00+08:00", time.Date(2024, 5, 1, 0, 30, 0, 0, time.UTC)},
+		{"24h", time.Date(2024, 5, 7, 12, 0, 0, 0, time.UTC)},
+		{"7d", time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+		{"1w", time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseTime(tt.in, now)
+		if err != nil {
+			t.Errorf("ParseTime(%q): %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseTime(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	for _, in := range []string{"yesterday", "-5h", "3x"} {
+		if _, err := ParseTime(in, now); err == nil {
+			t.Errorf("ParseTime(%q) succeeded, want error", in)
+		}
+	}
+}
+
+// 统计区块头请求次数的 Backend
+type headerCountingBackend struct {
+	Backend
+	headers int32
+}
+
+func (b *headerCountingBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	atomic.AddInt32(&b.headers, 1)
+	return b.Backend.HeaderByNumber(ctx, number)
+}
+
+func TestBlockTimeResolver(t *testing.T) {
+	// 区块 n 的时间为 1000 + 12n
+	fake := NewFakeBackend()
+	for n := uint64(0); n <= 100; n++ {
+		fake.AddBlock(testBlock(n), nil)
+	}
+	backend := &headerCountingBackend{Backend: fake}
+	resolver := NewBlockTimeResolver(backend)
+	ctx := context.Background()
+
+	at := func(ts int64) time.Time { return time.Unix(ts, 0) }
+	tests := []struct {
+		name string
+		fn   func() (uint64, error)
+		want uint64
+	}{
+		{"since exact", func() (uint64, error) { return resolver.BlockSince(ctx, at(1120), 100) }, 10},
+		{"since between", func() (uint64, error) { return resolver.BlockSince(ctx, at(1121), 100) }, 11},
+		{"since before genesis", func() (uint64, error) { return resolver.BlockSince(ctx, at(0), 100) }, 0},
+		{"until exact", func() (uint64, error) { return resolver.BlockUntil(ctx, at(1120), 100) }, 10},
+		{"until between", func() (uint64, error) { return resolver.BlockUntil(ctx, at(1131), 100) }, 10},
+		{"until future", func() (uint64, error) { return resolver.BlockUntil(ctx, at(99999), 100) }, 100},
+	}
+	for _, tt := range tests {
+		got, err := tt.fn()
+		if err != nil || got != tt.want {
+			t.Errorf("%s: got %d, %v; want %d", tt.name, got, err, tt.want)
+		}
+	}
+	if _, err := resolver.BlockSince(ctx, at(99999), 100); err == nil {
+		t.Error("expected error for a time after the latest block")
+	}
+	if _, err := resolver.BlockUntil(ctx, at(999), 100); err == nil {
+		t.Error("expected error for a time before the first block")
+	}
+
+	// 重复查询命中缓存
+	requests := atomic.LoadInt32(&backend.headers)
+	resolver.BlockSince(ctx, at(1120), 100)
+	if atomic.LoadInt32(&backend.headers) != requests {
+		t.Error("repeated lookup fetched headers again")
+	}
+}
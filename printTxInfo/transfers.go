@@ -0,0 +1,238 @@
+package printTxInfo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	transferTopic       = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	transferSingleTopic = crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+	transferBatchTopic  = crypto.Keccak256Hash([]byte("TransferBatch(address,address,address,uint256[],uint256[])"))
+
+	uint256ArrayType, _ = abi.NewType("uint256[]", "", nil)
+	transferBatchData   = abi.Arguments{{Type: uint256ArrayType}, {Type: uint256ArrayType}}
+)
+
+// 从回执日志中识别出的一次代币转移
+type Transfer struct {
+	Standard string // ERC20、ERC721 或 ERC1155
+	Token    common.Address
+	From     common.Address
+	To       common.Address
+	TokenID  *big.Int // ERC20 为 nil
+	Amount   *big.Int // ERC721 为 nil
+	LogIndex uint
+
+	Symbol   string // 未开启 -tokenInfo 或查询失败时为空
+	Decimals *uint8
+}
+
+type Transfers []Transfer
+
+func (ts Transfers) String() string {
+	items := make([]string, len(ts))
+	for i, t := range ts {
+		items[i] = t.String()
+	}
+	return "[" + strings.Join(items, "; ") + "]"
+}
+
+func (t Transfer) String() string {
+	token := t.Token.Hex()
+	if t.Symbol != "" {
+		token += "(" + t.Symbol + ")"
+	}
+	switch {
+	case t.Amount == nil:
+		return fmt.Sprintf("%s %s #%s %s -> %s", t.Standard, token, t.TokenID, t.From.Hex(), t.To.Hex())
+	case t.TokenID == nil:
+		return fmt.Sprintf("%s %s %s %s -> %s", t.Standard, token, t.FormatAmount(), t.From.Hex(), t.To.Hex())
+	default:
+		return fmt.Sprintf("%s %s #%s x%s %s -> %s", t.Standard, token, t.TokenID, t.FormatAmount(), t.From.Hex(), t.To.Hex())
+	}
+}
+
+// 按 decimals 格式化数量，例如 1500000 (decimals 6) -> 1.5；decimals 未知时返回原始整数
+func (t Transfer) FormatAmount() string {
+	if t.Amount == nil {
+		return ""
+	}
+	amount := t.Amount.String()
+	if t.Decimals != nil {
+		amount = FormatUnits(t.Amount, int(*t.Decimals))
+	}
+	if t.Symbol != "" && t.Decimals != nil {
+		amount += " " + t.Symbol
+	}
+	return amount
+}
+
+func FormatUnits(value *big.Int, decimals int) string {
+	if decimals <= 0 {
+		return value.String()
+	}
+	sign := ""
+	if value.Sign() < 0 {
+		sign = "-"
+	}
+	digits := new(big.Int).Abs(value).String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	intPart, fracPart := digits[:len(digits)-decimals], strings.TrimRight(digits[len(digits)-decimals:], "0")
+	if fracPart == "" {
+		return sign + intPart
+	}
+	return sign + intPart + "." + fracPart
+}
+
+// 识别标准的 Transfer、TransferSingle 和 TransferBatch 事件
+func ExtractTransfers(ctx context.Context, logs []*types.Log) Transfers {
+	var transfers Transfers
+	for _, l := range logs {
+		if len(l.Topics) == 0 {
+			continue
+		}
+		switch l.Topics[0] {
+		case transferTopic:
+			switch {
+			// ERC-20：from、to 为 indexed，数量在 data 中
+			case len(l.Topics) == 3 && len(l.Data) == 32:
+				transfers = append(transfers, Transfer{
+					Standard: "ERC20", Token: l.Address, LogIndex: l.Index,
+					From:   common.BytesToAddress(l.Topics[1].Bytes()),
+					To:     common.BytesToAddress(l.Topics[2].Bytes()),
+					Amount: new(big.Int).SetBytes(l.Data),
+				})
+			// ERC-721：tokenId 同样为 indexed
+			case len(l.Topics) == 4 && len(l.Data) == 0:
+				transfers = append(transfers, Transfer{
+					Standard: "ERC721", Token: l.Address, LogIndex: l.Index,
+					From:    common.BytesToAddress(l.Topics[1].Bytes()),
+					To:      common.BytesToAddress(l.Topics[2].Bytes()),
+					TokenID: l.Topics[3].Big(),
+				})
+			}
+		case transferSingleTopic:
+			if len(l.Topics) != 4 || len(l.Data) != 64 {
+				continue
+			}
+			transfers = append(transfers, Transfer{
+				Standard: "ERC1155", Token: l.Address, LogIndex: l.Index,
+				From:    common.BytesToAddress(l.Topics[2].Bytes()),
+				To:      common.BytesToAddress(l.Topics[3].Bytes()),
+				TokenID: new(big.Int).SetBytes(l.Data[:32]),
+				Amount:  new(big.Int).SetBytes(l.Data[32:]),
+			})
+		case transferBatchTopic:
+			if len(l.Topics) != 4 {
+				continue
+			}
+			values, err := transferBatchData.Unpack(l.Data)
+			if err != nil {
+				continue
+			}
+			ids, amounts := values[0].([]*big.Int), values[1].([]*big.Int)
+			if len(ids) != len(amounts) {
+				continue
+			}
+			for i := range ids {
+				transfers = append(transfers, Transfer{
+					Standard: "ERC1155", Token: l.Address, LogIndex: l.Index,
+					From:    common.BytesToAddress(l.Topics[2].Bytes()),
+					To:      common.BytesToAddress(l.Topics[3].Bytes()),
+					TokenID: ids[i],
+					Amount:  amounts[i],
+				})
+			}
+		}
+	}
+
+	if tokenCaller != nil {
+		for i := range transfers {
+			info := lookupTokenInfo(ctx, transfers[i].Token)
+			transfers[i].Symbol, transfers[i].Decimals = info.symbol, info.decimals
+		}
+	}
+	return transfers
+}
+
+type tokenInfo struct {
+	symbol   string
+	decimals *uint8
+}
+
+// 查询失败的代币最多尝试的次数，之后不再查询
+const maxTokenInfoAttempts = 3
+
+var (
+	tokenCaller   ethereum.ContractCaller
+	tokenMu       sync.Mutex
+	tokenCache    = make(map[common.Address]tokenInfo)
+	tokenFailures = make(map[common.Address]int)
+
+	symbolSelector   = crypto.Keccak256([]byte("symbol()"))[:4]
+	decimalsSelector = crypto.Keccak256([]byte("decimals()"))[:4]
+)
+
+// 开启后通过 eth_call 查询代币的 symbol 和 decimals，每个代币查询成功后不再查询
+func EnableTokenInfo(caller ethereum.ContractCaller) {
+	tokenCaller = caller
+}
+
+// 查询成功的结果直接缓存；失败的代币（节点错误或合约未实现）累计 maxTokenInfoAttempts 次后缓存空结果
+func lookupTokenInfo(ctx context.Context, token common.Address) tokenInfo {
+	tokenMu.Lock()
+	info, ok := tokenCache[token]
+	tokenMu.Unlock()
+	if ok {
+		return info
+	}
+
+	out, symbolErr := tokenCaller.CallContract(ctx, ethereum.CallMsg{To: &token, Data: symbolSelector}, nil)
+	if symbolErr == nil {
+		info.symbol = decodeSymbol(out)
+	}
+	out, decimalsErr := tokenCaller.CallContract(ctx, ethereum.CallMsg{To: &token, Data: decimalsSelector}, nil)
+	if decimalsErr == nil && len(out) == 32 {
+		if d := new(big.Int).SetBytes(out); d.IsUint64() && d.Uint64() <= 255 {
+			decimals := uint8(d.Uint64())
+			info.decimals = &decimals
+		}
+	}
+
+	tokenMu.Lock()
+	defer tokenMu.Unlock()
+	if symbolErr != nil || decimalsErr != nil {
+		tokenFailures[token]++
+		if tokenFailures[token] < maxTokenInfoAttempts {
+			return info
+		}
+	}
+	tokenCache[token] = info
+	return info
+}
+
+// symbol() 通常返回 string，部分早期代币（如 MKR）返回 bytes32
+func decodeSymbol(out []byte) string {
+	if len(out) == 32 {
+		return string(bytes.TrimRight(out, "\x00"))
+	}
+	stringType, _ := abi.NewType("string", "", nil)
+	values, err := abi.Arguments{{Type: stringType}}.Unpack(out)
+	if err != nil {
+		return ""
+	}
+	return values[0].(string)
+}
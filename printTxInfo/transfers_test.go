@@ -0,0 +1,205 @@
+package printTxInfo
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	testToken = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	testNFT   = common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	testMulti = common.HexToAddress("0x00000000000000000000000000000000000000cc")
+	testFrom  = common.HexToAddress("0x0000000000000000000000000000000000000001")
+	testTo    = common.HexToAddress("0x0000000000000000000000000000000000000002")
+)
+
+// symbol() 返回 USDC、decimals() 返回 6 的代币
+type fakeTokenCaller struct {
+	calls int
+}
+
+func (c *fakeTokenCaller) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	c.calls++
+	if *msg.To != testToken {
+		return nil, ethereum.NotFound
+	}
+	if string(msg.Data) == string(symbolSelector) {
+		stringType, _ := abi.NewType("string", "", nil)
+		return abi.Arguments{{Type: stringType}}.Pack("USDC")
+	}
+	return common.LeftPadBytes([]byte{6}, 32), nil
+}
+
+func transferLogs(t *testing.T) []*types.Log {
+	batch, err := transferBatchData.Pack([]*big.Int{big.NewInt(1), big.NewInt(2)}, []*big.Int{big.NewInt(10), big.NewInt(20)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	operator := common.BytesToHash(testFrom.Bytes())
+	from, to := common.BytesToHash(testFrom.Bytes()), common.BytesToHash(testTo.Bytes())
+	return []*types.Log{
+		{Address: testToken, Index: 0, Topics: []common.Hash{transferTopic, from, to}, Data: common.LeftPadBytes(big.NewInt(1500000).Bytes(), 32)},
+		{Address: testNFT, Index: 1, Topics: []common.Hash{transferTopic, from, to, common.BigToHash(big.NewInt(42))}},
+		{Address: testMulti, Index: 2, Topics: []common.Hash{transferSingleTopic, operator, from, to}, Data: append(common.LeftPadBytes([]byte{7}, 32), common.LeftPadBytes([]byte{3}, 32)...)},
+		{Address: testMulti, Index: 3, Topics: []common.Hash{transferBatchTopic, operator, from, to}, Data: batch},
+		{Address: testToken, Index: 4, Topics: []common.Hash{common.HexToHash("0x01")}},
+	}
+}
+
+func TestExtractTransfers(t *testing.T) {
+	defer EnableTokenInfo(nil)
+	logs := transferLogs(t)
+
+	transfers := ExtractTransfers(context.Background(), logs)
+	from, to := testFrom.Hex(), testTo.Hex()
+	want := []string{
+		"ERC20 " + testToken.Hex() + " 1500000 " + from + " -> " + to,
+		"ERC721 " + testNFT.Hex() + " #42 " + from + " -> " + to,
+		"ERC1155 " + testMulti.Hex() + " #7 x3 " + from + " -> " + to,
+		"ERC1155 " + testMulti.Hex() + " #1 x10 " + from + " -> " + to,
+		"ERC1155 " + testMulti.Hex() + " #2 x20 " + from + " -> " + to,
+	}
+	if len(transfers) != len(want) {
+		t.Fatalf("got %d transfers, want %d: %v", len(transfers), len(want), transfers)
+	}
+	for i := range want {
+		if got := transfers[i].String(); got != want[i] {
+			t.Errorf("transfer %d:\n got %s\nwant %s", i, got, want[i])
+		}
+	}
+
+	// 开启代币信息后按 decimals 格式化，查询成功的代币只查询一次
+	caller := &fakeTokenCaller{}
+	EnableTokenInfo(caller)
+	resetTokenCache(t)
+	transfers = ExtractTransfers(context.Background(), logs)
+	ExtractTransfers(context.Background(), logs)
+	if got := transfers[0].FormatAmount(); got != "1.5 USDC" {
+		t.Errorf("FormatAmount = %q, want 1.5 USDC", got)
+	}
+	if transfers[1].Symbol != "" || transfers[1].Decimals != nil {
+		t.Errorf("unexpected token info for NFT: %+v", transfers[1])
+	}
+	// USDC 查询一次；NFT 两次调用各失败一次；ERC1155 代币第一次调用中出现 3 次，失败 3 次后不再查询
+	if caller.calls != 2+2*2+3*2 {
+		t.Errorf("made %d eth_calls, want 12", caller.calls)
+	}
+}
+
+func resetTokenCache(t *testing.T) {
+	tokenMu.Lock()
+	defer tokenMu.Unlock()
+	tokenCache = make(map[common.Address]tokenInfo)
+	tokenFailures = make(map[common.Address]int)
+}
+
+// 前 failures 次调用返回限流错误
+type flakyTokenCaller struct {
+	fakeTokenCaller
+	failures int
+}
+
+func (c *flakyTokenCaller) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if c.failures > 0 {
+		c.failures--
+		c.calls++
+		return nil, rpc.HTTPError{StatusCode: 429, Status: "429 Too Many Requests"}
+	}
+	return c.fakeTokenCaller.CallContract(ctx, msg, blockNumber)
+}
+
+func TestLookupTokenInfoRetriesFailures(t *testing.T) {
+	defer EnableTokenInfo(nil)
+	resetTokenCache(t)
+	caller := &flakyTokenCaller{failures: 2}
+	EnableTokenInfo(caller)
+	ctx := context.Background()
+
+	// 临时错误不会被缓存，下一次查询成功
+	if info := lookupTokenInfo(ctx, testToken); info.symbol != "" || info.decimals != nil {
+		t.Errorf("info after failures = %+v, want empty", info)
+	}
+	info := lookupTokenInfo(ctx, testToken)
+	if info.symbol != "USDC" || info.decimals == nil || *info.decimals != 6 {
+		t.Errorf("info = %+v, want USDC with 6 decimals", info)
+	}
+	lookupTokenInfo(ctx, testToken)
+	if caller.calls != 4 {
+		t.Errorf("made %d eth_calls, want 4", caller.calls)
+	}
+
+	// 一直失败的代币在 maxTokenInfoAttempts 次后不再查询
+	caller.calls = 0
+	for i := 0; i < maxTokenInfoAttempts+2; i++ {
+		lookupTokenInfo(ctx, testNFT)
+	}
+	if caller.calls != 2*maxTokenInfoAttempts {
+		t.Errorf("made %d eth_calls for a failing token, want %d", caller.calls, 2*maxTokenInfoAttempts)
+	}
+}
+
+func TestFormatUnits(t *testing.T) {
+	tests := []struct {
+		value    int64
+		decimals int
+		want     string
+	}{
+		{1500000, 6, "1.5"},
+		{1, 6, "0.000001"},
+		{1000000, 6, "1"},
+		{0, 18, "0"},
+		{-2500, 3, "-2.5"},
+		{42, 0, "42"},
+	}
+	for _, tt := range tests {
+		if got := FormatUnits(big.NewInt(tt.value), tt.decimals); got != tt.want {
+			t.Errorf("FormatUnits(%d, %d) = %q, want %q", tt.value, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+// 带 eth_call 的 Backend，前 failures 次调用返回限流错误
+type callerBackend struct {
+	*FakeBackend
+	fakeTokenCaller
+	failures int
+}
+
+func (b *callerBackend) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if b.failures > 0 {
+		b.failures--
+		return nil, rpc.HTTPError{StatusCode: 429, Status: "429 Too Many Requests"}
+	}
+	return b.fakeTokenCaller.CallContract(ctx, msg, blockNumber)
+}
+
+// 代币信息查询经过与扫描相同的限速、重试和缓存包装
+func TestTokenInfoThroughWrappedBackend(t *testing.T) {
+	inner := &callerBackend{FakeBackend: NewFakeBackend(), failures: 2}
+	var backend Backend = NewRetryBackend(NewThrottledBackend(inner, 0, nil), testRetryConfig)
+	backend = &CachedBackend{Backend: backend}
+
+	caller, ok := backend.(ethereum.ContractCaller)
+	if !ok {
+		t.Fatal("wrapped backend does not implement ethereum.ContractCaller")
+	}
+	out, err := caller.CallContract(context.Background(), ethereum.CallMsg{To: &testToken, Data: decimalsSelector}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 32 || out[31] != 6 || inner.calls != 1 || inner.failures != 0 {
+		t.Errorf("out = %x, calls = %d, failures left = %d", out, inner.calls, inner.failures)
+	}
+
+	if _, err := NewRetryBackend(NewFakeBackend(), testRetryConfig).CallContract(context.Background(), ethereum.CallMsg{To: &testToken}, nil); !errors.Is(err, errNoContractCaller) {
+		t.Errorf("err = %v, want errNoContractCaller", err)
+	}
+}
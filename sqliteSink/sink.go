@@ -0,0 +1,265 @@
+package sqliteSink
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	_ "github.com/mattn/go-sqlite3"
+
+	"test/blobTx/printTxInfo"
+)
+
+// 扫描结果的 SQLite 存储：交易、回执、解码后的参数和日志分表保存，按交易哈希去重，
+// 多次扫描可写入同一个数据库
+const schema = `
+CREATE TABLE IF NOT EXISTS scans (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	started_at   INTEGER NOT NULL,
+	contract     TEXT NOT NULL,
+	start_block  INTEGER NOT NULL,
+	end_block    INTEGER NOT NULL,
+	inserted     INTEGER NOT NULL,
+	skipped      INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS transactions (
+	hash          TEXT PRIMARY KEY,
+	block_number  INTEGER NOT NULL,
+	block_hash    TEXT NOT NULL,
+	tx_index      INTEGER NOT NULL,
+	type          INTEGER NOT NULL,
+	from_address  TEXT NOT NULL,
+	to_address    TEXT,
+	nonce         INTEGER NOT NULL,
+	value         TEXT NOT NULL,
+	gas           INTEGER NOT NULL,
+	gas_price     TEXT NOT NULL,
+	gas_tip_cap   TEXT NOT NULL,
+	gas_fee_cap   TEXT NOT NULL,
+	blob_fee_cap  TEXT,
+	blob_count    INTEGER NOT NULL,
+	selector      TEXT,
+	func          TEXT,
+	input         TEXT NOT NULL,
+	scan_id       INTEGER REFERENCES scans(id)
+);
+CREATE INDEX IF NOT EXISTS transactions_block ON transactions(block_number, tx_index);
+CREATE INDEX IF NOT EXISTS transactions_selector ON transactions(selector);
+CREATE INDEX IF NOT EXISTS transactions_from ON transactions(from_address);
+CREATE INDEX IF NOT EXISTS transactions_to ON transactions(to_address);
+
+CREATE TABLE IF NOT EXISTS receipts (
+	tx_hash              TEXT PRIMARY KEY REFERENCES transactions(hash),
+	status               INTEGER NOT NULL,
+	gas_used             INTEGER NOT NULL,
+	cumulative_gas_used  INTEGER NOT NULL,
+	effective_gas_price  TEXT,
+	blob_gas_used        INTEGER NOT NULL,
+	blob_gas_price       TEXT,
+	contract_address     TEXT
+);
+
+CREATE TABLE IF NOT EXISTS tx_args (
+	tx_hash   TEXT NOT NULL REFERENCES transactions(hash),
+	position  INTEGER NOT NULL,
+	name      TEXT NOT NULL,
+	type      TEXT NOT NULL,
+	value     TEXT NOT NULL,
+	PRIMARY KEY (tx_hash, position)
+);
+
+CREATE TABLE IF NOT EXISTS logs (
+	tx_hash       TEXT NOT NULL REFERENCES transactions(hash),
+	log_index     INTEGER NOT NULL,
+	block_number  INTEGER NOT NULL,
+	address       TEXT NOT NULL,
+	event         TEXT,
+	topic0        TEXT,
+	topic1        TEXT,
+	topic2        TEXT,
+	topic3        TEXT,
+	data          TEXT NOT NULL,
+	PRIMARY KEY (tx_hash, log_index)
+);
+CREATE INDEX IF NOT EXISTS logs_block ON logs(block_number);
+CREATE INDEX IF NOT EXISTS logs_address ON logs(address);
+CREATE INDEX IF NOT EXISTS logs_topic0 ON logs(topic0);
+
+CREATE TABLE IF NOT EXISTS log_args (
+	tx_hash    TEXT NOT NULL,
+	log_index  INTEGER NOT NULL,
+	position   INTEGER NOT NULL,
+	name       TEXT NOT NULL,
+	type       TEXT NOT NULL,
+	indexed    INTEGER NOT NULL,
+	value      TEXT NOT NULL,
+	PRIMARY KEY (tx_hash, log_index, position),
+	FOREIGN KEY (tx_hash, log_index) REFERENCES logs(tx_hash, log_index)
+);
+`
+
+type Sink struct {
+	db *sql.DB
+}
+
+// 本次扫描的范围，记录在 scans 表中
+type Scan struct {
+	Contract   common.Address
+	StartBlock uint64
+	EndBlock   uint64
+}
+
+func Open(path string) (*Sink, error) {
+	db, err := sql.Open("sqlite3", path+"?_foreign_keys=on&_journal_mode=WAL")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+	return &Sink{db: db}, nil
+}
+
+func (s *Sink) Close() error {
+	return s.db.Close()
+}
+
+func (s *Sink) DB() *sql.DB {
+	return s.db
+}
+
+// 在一个事务中写入扫描结果，已存在的交易跳过，返回新写入和跳过的交易数
+func (s *Sink) Write(ctx context.Context, scan Scan, infos []printTxInfo.TxInfo) (int, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `INSERT INTO scans (started_at, contract, start_block, end_block, inserted, skipped) VALUES (?, ?, ?, ?, 0, 0)`,
+		time.Now().Unix(), scan.Contract.Hex(), scan.StartBlock, scan.EndBlock)
+	if err != nil {
+		return 0, 0, err
+	}
+	scanID, err := res.LastInsertId()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var inserted, skipped int
+	for _, info := range infos {
+		if info.Tx == nil || info.Receipt == nil {
+			continue
+		}
+		ok, err := writeTx(ctx, tx, scanID, info)
+		if err != nil {
+			return 0, 0, fmt.Errorf("tx %s: %w", info.Tx.Hash().Hex(), err)
+		}
+		if ok {
+			inserted++
+		} else {
+			skipped++
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE scans SET inserted = ?, skipped = ? WHERE id = ?`, inserted, skipped, scanID); err != nil {
+		return 0, 0, err
+	}
+	return inserted, skipped, tx.Commit()
+}
+
+func writeTx(ctx context.Context, tx *sql.Tx, scanID int64, info printTxInfo.TxInfo) (bool, error) {
+	t, r := info.Tx, info.Receipt
+
+	from, err := types.Sender(types.LatestSignerForChainID(t.ChainId()), t)
+	if err != nil {
+		return false, fmt.Errorf("failed to recover sender: %w", err)
+	}
+	var to, selector, funcName interface{}
+	if t.To() != nil {
+		to = t.To().Hex()
+	}
+	if len(t.Data()) >= 4 {
+		selector = "0x" + hex.EncodeToString(t.Data()[:4])
+	}
+	name, args, decoded, err := printTxInfo.DecodeCalldata(t.Data())
+	if err != nil {
+		log.Printf("Failed to decode calldata of tx %s: %v", t.Hash().Hex(), err)
+	}
+	if decoded {
+		funcName = name
+	}
+
+	res, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO transactions
+		(hash, block_number, block_hash, tx_index, type, from_address, to_address, nonce, value, gas, gas_price, gas_tip_cap, gas_fee_cap, blob_fee_cap, blob_count, selector, func, input, scan_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.Hash().Hex(), r.BlockNumber.Uint64(), r.BlockHash.Hex(), r.TransactionIndex, t.Type(), from.Hex(), to,
+		t.Nonce(), t.Value().String(), t.Gas(), t.GasPrice().String(), t.GasTipCap().String(), t.GasFeeCap().String(),
+		bigOrNil(t.BlobGasFeeCap()), len(t.BlobHashes()), selector, funcName, "0x"+hex.EncodeToString(t.Data()), scanID)
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	var contract interface{}
+	if r.ContractAddress != (common.Address{}) {
+		contract = r.ContractAddress.Hex()
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO receipts
+		(tx_hash, status, gas_used, cumulative_gas_used, effective_gas_price, blob_gas_used, blob_gas_price, contract_address)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.Hash().Hex(), r.Status, r.GasUsed, r.CumulativeGasUsed, bigOrNil(r.EffectiveGasPrice), r.BlobGasUsed, bigOrNil(r.BlobGasPrice), contract); err != nil {
+		return false, err
+	}
+
+	for i, arg := range args {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO tx_args (tx_hash, position, name, type, value) VALUES (?, ?, ?, ?, ?)`,
+			t.Hash().Hex(), i, arg.Name, arg.Type, arg.Value); err != nil {
+			return false, err
+		}
+	}
+
+	for _, l := range r.Logs {
+		decodedLog, err := printTxInfo.DecodeLog(l)
+		if err != nil {
+			log.Printf("Failed to decode log %d of tx %s: %v", l.Index, t.Hash().Hex(), err)
+		}
+		var event interface{}
+		if decodedLog.Event != "" {
+			event = decodedLog.Event
+		}
+		topics := make([]interface{}, 4)
+		for i := 0; i < len(l.Topics) && i < 4; i++ {
+			topics[i] = l.Topics[i].Hex()
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO logs (tx_hash, log_index, block_number, address, event, topic0, topic1, topic2, topic3, data)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			t.Hash().Hex(), l.Index, r.BlockNumber.Uint64(), l.Address.Hex(), event, topics[0], topics[1], topics[2], topics[3], "0x"+hex.EncodeToString(l.Data)); err != nil {
+			return false, err
+		}
+		for i, arg := range decodedLog.Args {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO log_args (tx_hash, log_index, position, name, type, indexed, value) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+				t.Hash().Hex(), l.Index, i, arg.Name, arg.Type, arg.Indexed, arg.Value); err != nil {
+				return false, err
+			}
+		}
+	}
+	return true, nil
+}
+
+func bigOrNil(v *big.Int) interface{} {
+	if v == nil {
+		return nil
+	}
+	return v.String()
+}
@@ -0,0 +1,128 @@
+package sqliteSink
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"test/blobTx/printTxInfo"
+)
+
+const testSignatures = `[
+  {"type": "function", "name": "transfer(address,uint256)", "signature": "0xa9059cbb",
+   "inputs": [{"name": "to", "type": "address"}, {"name": "amount", "type": "uint256"}]},
+  {"type": "event", "name": "Transfer(address,address,uint256)", "signature": "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
+   "inputs": [{"name": "from", "type": "address", "indexed": true}, {"name": "to", "type": "address", "indexed": true}, {"name": "value", "type": "uint256", "indexed": false}]}
+]`
+
+var (
+	testChainID  = big.NewInt(1337)
+	testContract = common.HexToAddress("0xcf7ed3acca5a467e9e704c703e8d87f634fb0fc9")
+	testTo       = common.HexToAddress("0x000000000000000000000000000000000000dead")
+)
+
+func testInfos(t *testing.T) []printTxInfo.TxInfo {
+	path := filepath.Join(t.TempDir(), "signatures.json")
+	if err := os.WriteFile(path, []byte(testSignatures), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := printTxInfo.LoadSignatures(path); err != nil {
+		t.Fatal(err)
+	}
+
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	data := hexutil.MustDecode("0xa9059cbb000000000000000000000000000000000000000000000000000000000000dead0000000000000000000000000000000000000000000000000000000000000064")
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(testChainID), &types.DynamicFeeTx{
+		ChainID: testChainID, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(10), Gas: 60000, To: &testContract, Data: data,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt := &types.Receipt{
+		Status:            types.ReceiptStatusSuccessful,
+		GasUsed:           51000,
+		CumulativeGasUsed: 51000,
+		EffectiveGasPrice: big.NewInt(2),
+		TxHash:            tx.Hash(),
+		BlockNumber:       big.NewInt(5),
+		Logs: []*types.Log{{
+			Address: testContract,
+			Topics: []common.Hash{
+				common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"),
+				common.BytesToHash(from.Bytes()),
+				common.BytesToHash(testTo.Bytes()),
+			},
+			Data:  common.LeftPadBytes([]byte{100}, 32),
+			Index: 3,
+		}},
+	}
+	return []printTxInfo.TxInfo{{BlockNumber: 5, Tx: tx, Receipt: receipt}}
+}
+
+func TestWrite(t *testing.T) {
+	infos := testInfos(t)
+	path := filepath.Join(t.TempDir(), "scan.db")
+	scan := Scan{Contract: testContract, StartBlock: 0, EndBlock: 10}
+
+	for run, want := range []struct{ inserted, skipped int }{{1, 0}, {0, 1}} {
+		sink, err := Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inserted, skipped, err := sink.Write(context.Background(), scan, infos)
+		sink.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if inserted != want.inserted || skipped != want.skipped {
+			t.Fatalf("run %d: inserted %d, skipped %d; want %d, %d", run, inserted, skipped, want.inserted, want.skipped)
+		}
+	}
+
+	sink, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	db := sink.DB()
+
+	var scans, txs int
+	db.QueryRow(`SELECT COUNT(*) FROM scans`).Scan(&scans)
+	db.QueryRow(`SELECT COUNT(*) FROM transactions`).Scan(&txs)
+	if scans != 2 || txs != 1 {
+		t.Fatalf("got %d scans and %d transactions, want 2 and 1", scans, txs)
+	}
+
+	var funcName, selector string
+	var gasUsed uint64
+	err = db.QueryRow(`SELECT t.func, t.selector, r.gas_used FROM transactions t JOIN receipts r ON r.tx_hash = t.hash WHERE t.block_number = 5`).Scan(&funcName, &selector, &gasUsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if funcName != "transfer(address,uint256)" || selector != "0xa9059cbb" || gasUsed != 51000 {
+		t.Fatalf("unexpected row: %s %s %d", funcName, selector, gasUsed)
+	}
+
+	var amount string
+	if err := db.QueryRow(`SELECT value FROM tx_args WHERE name = 'amount'`).Scan(&amount); err != nil || amount != "100" {
+		t.Fatalf("amount = %q, %v; want 100", amount, err)
+	}
+
+	var event, to string
+	err = db.QueryRow(`SELECT l.event, a.value FROM logs l JOIN log_args a ON a.tx_hash = l.tx_hash AND a.log_index = l.log_index
+		WHERE l.address = ? AND a.name = 'to'`, testContract.Hex()).Scan(&event, &to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event != "Transfer(address,address,uint256)" || to != testTo.Hex() {
+		t.Fatalf("unexpected log row: %s %s", event, to)
+	}
+}